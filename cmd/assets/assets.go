@@ -7,7 +7,7 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "assets",
 	Short: "Manage bookmark assets",
-	Long:  "Commands for listing, uploading, downloading, and deleting bookmark assets (file attachments).",
+	Long:  "Commands for listing, uploading, downloading, mirroring, and deleting bookmark assets (file attachments).",
 }
 
 func init() {
@@ -15,5 +15,6 @@ func init() {
 	Cmd.AddCommand(getCmd)
 	Cmd.AddCommand(uploadCmd)
 	Cmd.AddCommand(downloadCmd)
+	Cmd.AddCommand(mirrorCmd)
 	Cmd.AddCommand(deleteCmd)
 }
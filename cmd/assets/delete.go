@@ -1,16 +1,12 @@
 package assets
 
 import (
-	"bufio"
-	"context"
 	"fmt"
-	"os"
 	"strconv"
-	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -34,20 +30,20 @@ func init() {
 func runDelete(cobraCmd *cobra.Command, args []string) error {
 	bookmarkID, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	assetID, err := strconv.Atoi(args[1])
 	if err != nil {
-		return fmt.Errorf("invalid asset ID: %s", args[1])
+		return exitcode.Usagef("invalid asset ID: %s", args[1])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	assetsAPI := api.NewAssetsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 
 	// Get asset details for confirmation message
 	asset, err := assetsAPI.Get(ctx, bookmarkID, assetID)
@@ -56,18 +52,15 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm deletion unless --force is used
-	if !deleteForce && formatter.IsTTY() {
-		fmt.Printf("Delete asset #%d \"%s\"? [y/N]: ", asset.ID, asset.DisplayName)
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	if !deleteForce && !cfg.DryRun && formatter.IsTTY() {
+		confirmed, err := cmd.Confirm(fmt.Sprintf("Delete asset #%d \"%s\"?", asset.ID, asset.DisplayName))
 		if err != nil {
 			return err
 		}
 
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			formatter.Println("Aborted.")
-			return nil
+			return exitcode.ErrAborted
 		}
 	}
 
@@ -76,7 +69,12 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	if cfg.DryRun {
+		formatter.DryRun("would delete asset #%d \"%s\"", asset.ID, asset.DisplayName)
+		return nil
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
 		formatter.Success("Asset #%d deleted", assetID)
 	}
 
@@ -1,65 +1,131 @@
 package assets
 
 import (
-	"context"
+	"encoding/hex"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
 	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
-var downloadOutput string
+var (
+	downloadDest     string
+	downloadAll      bool
+	downloadForce    bool
+	downloadChecksum bool
+	downloadSHA256   string
+)
 
 var downloadCmd = &cobra.Command{
 	Use:   "download <bookmark-id> <asset-id> [output-path]",
 	Short: "Download an asset",
-	Long:  "Download an asset file from a bookmark.",
+	Long: `Download an asset file from a bookmark.
+
+The file is written under the output path plus ".part" and renamed when
+complete, so an interrupted download never leaves a truncated file
+behind. Running the command again resumes the part where it stopped, if
+the server supports it. An existing file is only overwritten with
+--force. A progress bar is shown when stderr is a terminal.
+
+--checksum prints the SHA-256 of the downloaded file, and --sha256
+checks it against an expected digest, discarding the download if they
+differ.
+
+With --all, every asset of the bookmark is downloaded into the directory
+given by -o (default: the current directory), named after the assets.
+Files that already exist with the expected size are skipped; files with
+a different size are only replaced with --force. The size of each
+download is recorded in .clinkding-assets.json in the directory, since
+the size the server lists for a compressed snapshot can differ from the
+bytes it sends.`,
 	Example: `  clinkding assets download 42 1
   clinkding assets download 42 1 ~/Downloads/myfile.pdf
-  clinkding assets download 42 1 -o ./screenshot.png`,
-	Args: cobra.RangeArgs(2, 3),
+  clinkding assets download 42 1 -o ./screenshot.png --force
+  clinkding assets download 42 1 --sha256 9f86d081884c7d65...
+  clinkding assets download 42 --all -o ./bookmark-42`,
+	Args: cobra.RangeArgs(1, 3),
 	RunE: runDownload,
 }
 
 func init() {
-	downloadCmd.Flags().StringVarP(&downloadOutput, "output", "o", "", "output file path")
+	downloadCmd.Flags().StringVarP(&downloadDest, "dest", "o", "", "file to write (directory with --all)")
+	downloadCmd.Flags().BoolVar(&downloadAll, "all", false, "download every asset of the bookmark")
+	downloadCmd.Flags().BoolVarP(&downloadForce, "force", "f", false, "overwrite existing files")
+	downloadCmd.Flags().BoolVar(&downloadChecksum, "checksum", false, "print the SHA-256 of the downloaded file")
+	downloadCmd.Flags().StringVar(&downloadSHA256, "sha256", "", "verify the download against this SHA-256 (hex)")
+}
+
+// downloadResult describes a downloaded file, for JSON output.
+type downloadResult struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// downloadAllResult describes one asset of download --all, for the
+// machine-readable formats. Status is "downloaded", "present" or
+// "conflict" for a different file that was left in place.
+type downloadAllResult struct {
+	AssetID int    `json:"asset_id"`
+	Path    string `json:"path"`
+	Status  string `json:"status"`
+	Bytes   int64  `json:"bytes"`
+	SHA256  string `json:"sha256,omitempty"`
 }
 
 func runDownload(cobraCmd *cobra.Command, args []string) error {
 	bookmarkID, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
+	}
+	if downloadAll {
+		if len(args) > 1 {
+			return exitcode.Usagef("--all takes only a bookmark ID; use -o to choose the directory")
+		}
+		if downloadChecksum || downloadSHA256 != "" {
+			return exitcode.Usagef("--checksum and --sha256 apply to a single asset, not --all")
+		}
+		return runDownloadAll(cobraCmd, bookmarkID)
+	}
+	if len(args) < 2 {
+		return exitcode.Usagef("missing asset ID (or use --all to download every asset)")
 	}
 
 	assetID, err := strconv.Atoi(args[1])
 	if err != nil {
-		return fmt.Errorf("invalid asset ID: %s", args[1])
+		return exitcode.Usagef("invalid asset ID: %s", args[1])
+	}
+	if downloadSHA256 != "" && !validSHA256(downloadSHA256) {
+		return exitcode.Usagef("invalid --sha256 %q: expected 64 hex digits", downloadSHA256)
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	assetsAPI := api.NewAssetsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
+
+	// The asset's size is the progress total when the server sends no
+	// Content-Length, and its display name the default output path
+	asset, err := assetsAPI.Get(ctx, bookmarkID, assetID)
+	if err != nil {
+		return err
+	}
 
 	// Determine output path
-	outputPath := downloadOutput
+	outputPath := downloadDest
 	if outputPath == "" && len(args) >= 3 {
 		outputPath = args[2]
 	}
-
-	// If still no output path, get asset details to use its display name
 	if outputPath == "" {
-		asset, err := assetsAPI.Get(ctx, bookmarkID, assetID)
-		if err != nil {
-			return err
-		}
 		outputPath = asset.DisplayName
 	}
 
@@ -68,19 +134,167 @@ func runDownload(cobraCmd *cobra.Command, args []string) error {
 		outputPath = fmt.Sprintf("asset-%d", assetID)
 	}
 
-	if !cfg.Quiet {
-		formatter.Println("Downloading to %s...", outputPath)
+	if info, err := os.Stat(outputPath); err == nil {
+		if info.IsDir() {
+			return exitcode.Usagef("%s is a directory; give a file path", outputPath)
+		}
+		if !downloadForce {
+			return exitcode.Usagef("%s already exists (use --force to overwrite)", outputPath)
+		}
+	}
+
+	human := cfg.HumanOutput() && !cfg.Quiet
+	progress := formatter.NewProgress(filepath.Base(outputPath), asset.FileSize)
+	if human {
+		if info, err := os.Stat(outputPath + ".part"); err == nil && info.Size() > 0 {
+			formatter.Info("Resuming an earlier download (%s done)", output.FormatBytes(info.Size()))
+		}
+		if !progress.Active() {
+			formatter.Println("Downloading to %s...", outputPath)
+		}
 	}
 
 	// Download the file
-	if err := assetsAPI.Download(ctx, bookmarkID, assetID, outputPath); err != nil {
+	opts := &client.Download{Size: asset.FileSize, SHA256: downloadSHA256, Checksum: downloadChecksum, Progress: progress.Update}
+	sum, err := assetsAPI.DownloadWith(ctx, bookmarkID, assetID, outputPath, opts)
+	progress.Done()
+	if err != nil {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	// Output based on format
+	if !cfg.HumanOutput() {
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			return err
+		}
+		result := downloadResult{Path: outputPath, Bytes: info.Size(), SHA256: sum}
+		return formatter.PrintRecord(result, output.Columns{Plain: []string{"path", "sha256"}})
+	}
+
+	if !cfg.Quiet {
 		absPath, _ := filepath.Abs(outputPath)
 		formatter.Success("Asset downloaded to: %s", absPath)
+		switch {
+		case downloadSHA256 != "":
+			formatter.Println("SHA-256:     %s (verified)", sum)
+		case downloadChecksum:
+			formatter.Println("SHA-256:     %s", sum)
+		}
 	}
 
 	return nil
 }
+
+// validSHA256 reports whether s looks like a hex SHA-256 digest.
+func validSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func runDownloadAll(cobraCmd *cobra.Command, bookmarkID int) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	assetsAPI := api.NewAssetsAPI(httpClient)
+	formatter := output.New(cfg)
+
+	dir := downloadDest
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	ctx := cobraCmd.Context()
+	assets, err := assetsAPI.Iterate(bookmarkID).All(ctx)
+	if err != nil {
+		return err
+	}
+	if len(assets) == 0 && cfg.HumanOutput() {
+		formatter.Info("Bookmark #%d has no assets", bookmarkID)
+		return nil
+	}
+
+	names := assetFileNames(assets)
+	records := readDownloadManifest(dir)
+	results := make([]downloadAllResult, 0, len(assets))
+	fetched, skipped, conflicts := 0, 0, 0
+	for i, asset := range assets {
+		path := filepath.Join(dir, names[asset.ID])
+		result := downloadAllResult{AssetID: asset.ID, Path: path}
+		var record *downloadRecord
+		if r, ok := records[asset.ID]; ok {
+			record = &r
+		}
+		if downloaded(path, record, asset.FileSize) {
+			skipped++
+			result.Status = "present"
+			if info, err := os.Stat(path); err == nil {
+				result.Bytes = info.Size()
+			}
+			if record != nil {
+				result.SHA256 = record.SHA256
+			}
+			results = append(results, result)
+			if cfg.Verbose && cfg.HumanOutput() {
+				formatter.Println("[%d/%d] %s already downloaded", i+1, len(assets), path)
+			}
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !downloadForce {
+			conflicts++
+			result.Status = "conflict"
+			result.Bytes = info.Size()
+			results = append(results, result)
+			if cfg.HumanOutput() {
+				formatter.Warning("[%d/%d] %s already exists with a different size", i+1, len(assets), path)
+			}
+			continue
+		}
+
+		progress := formatter.NewProgress(fmt.Sprintf("[%d/%d] %s", i+1, len(assets), names[asset.ID]), asset.FileSize)
+		if !cfg.Quiet && cfg.HumanOutput() && !progress.Active() {
+			formatter.Println("[%d/%d] %s", i+1, len(assets), path)
+		}
+		r, err := downloadAsset(ctx, assetsAPI, asset, path, progress.Update)
+		progress.Done()
+		if err != nil {
+			return fmt.Errorf("failed to download asset #%d: %w", asset.ID, err)
+		}
+		records[asset.ID] = r
+		if err := writeDownloadManifest(dir, records); err != nil {
+			return err
+		}
+		result.Status, result.Bytes, result.SHA256 = "downloaded", r.Bytes, r.SHA256
+		results = append(results, result)
+		if !cfg.Quiet && cfg.HumanOutput() && progress.Active() {
+			formatter.Println("[%d/%d] %s", i+1, len(assets), path)
+		}
+		fetched++
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		enc := formatter.NewEncoder(output.Columns{Plain: []string{"status", "path"}})
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	} else if !cfg.Quiet {
+		absDir, _ := filepath.Abs(dir)
+		formatter.Success("Downloaded %d assets to %s (%d already present)", fetched, absDir, skipped)
+	}
+
+	if conflicts > 0 {
+		return fmt.Errorf("%d files already exist with a different size; use --force to replace them", conflicts)
+	}
+	return nil
+}
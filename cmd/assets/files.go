@@ -0,0 +1,133 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/atomicfile"
+	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// assetFileName returns a safe local file name for an asset: its display
+// name, with the extension of the stored file added when the display name
+// has none (snapshots are named like "HTML snapshot from ...").
+func assetFileName(asset models.Asset) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(asset.DisplayName))
+	if name == "" || name == "." || name == ".." {
+		name = "asset-" + strconv.Itoa(asset.ID)
+	}
+	if filepath.Ext(name) == "" {
+		name += fileExt(path.Base(asset.File))
+	}
+	return name
+}
+
+// fileExt returns the extension of a stored file name, keeping compression
+// suffixes with the extension before them (".html.gz").
+func fileExt(name string) string {
+	ext := path.Ext(name)
+	switch ext {
+	case ".gz", ".bz2", ".xz", ".zst":
+		ext = path.Ext(strings.TrimSuffix(name, ext)) + ext
+	}
+	if strings.ContainsAny(ext, " /\\") {
+		return ""
+	}
+	return ext
+}
+
+// assetFileNames names each asset of one bookmark. Assets sharing a name
+// get their ID as a prefix, so no file overwrites another.
+func assetFileNames(assets []models.Asset) map[int]string {
+	count := make(map[string]int)
+	for _, asset := range assets {
+		count[strings.ToLower(assetFileName(asset))]++
+	}
+	names := make(map[int]string, len(assets))
+	for _, asset := range assets {
+		name := assetFileName(asset)
+		if count[strings.ToLower(name)] > 1 {
+			name = fmt.Sprintf("%d-%s", asset.ID, name)
+		}
+		names[asset.ID] = name
+	}
+	return names
+}
+
+// downloadRecord is what an earlier run downloaded for an asset. Later
+// runs compare files against it rather than the asset's file_size, which
+// for snapshots stored compressed needn't be the size the server sends.
+type downloadRecord struct {
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// downloaded reports whether path holds a complete earlier download: a
+// regular file of the recorded size or, without a record, of size bytes.
+func downloaded(path string, record *downloadRecord, size int64) bool {
+	if record != nil && record.Bytes > 0 {
+		size = record.Bytes
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Size() == size
+}
+
+// downloadAsset downloads an asset to path and returns what was written.
+// The client writes it to path+".part" first and renames it when
+// complete, so an interrupted download never leaves a partial file under
+// the final name, and the next attempt resumes the part.
+func downloadAsset(ctx context.Context, assetsAPI *api.AssetsAPI, asset models.Asset, path string, progress func(written, total int64)) (downloadRecord, error) {
+	sum, err := assetsAPI.DownloadWith(ctx, asset.BookmarkID, asset.ID, path, &client.Download{Size: asset.FileSize, Checksum: true, Progress: progress})
+	if err != nil {
+		return downloadRecord{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return downloadRecord{}, err
+	}
+	return downloadRecord{Bytes: info.Size(), SHA256: sum}, nil
+}
+
+// downloadManifestFile records what download --all wrote to a directory,
+// hidden so it doesn't clash with an asset's name.
+const downloadManifestFile = ".clinkding-assets.json"
+
+// readDownloadManifest reads the records of download --all in dir, by
+// asset ID. A missing or unreadable manifest means no records.
+func readDownloadManifest(dir string) map[int]downloadRecord {
+	records := make(map[int]downloadRecord)
+	data, err := os.ReadFile(filepath.Join(dir, downloadManifestFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &records)
+	}
+	return records
+}
+
+// writeDownloadManifest writes the records of download --all in dir.
+func writeDownloadManifest(dir string, records map[int]downloadRecord) error {
+	return writeManifest(filepath.Join(dir, downloadManifestFile), records)
+}
+
+// writeManifest writes a manifest as indented JSON, replacing the file
+// atomically so a failed write leaves the previous one intact.
+func writeManifest(path string, manifest interface{}) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'), 0o644)
+}
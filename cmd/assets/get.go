@@ -1,14 +1,12 @@
 package assets
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -25,37 +23,28 @@ var getCmd = &cobra.Command{
 func runGet(cobraCmd *cobra.Command, args []string) error {
 	bookmarkID, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	assetID, err := strconv.Atoi(args[1])
 	if err != nil {
-		return fmt.Errorf("invalid asset ID: %s", args[1])
+		return exitcode.Usagef("invalid asset ID: %s", args[1])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	assetsAPI := api.NewAssetsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	asset, err := assetsAPI.Get(ctx, bookmarkID, assetID)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(asset)
-	}
-
-	if cfg.OutputPlain {
-		output.PrintPlainLine(
-			strconv.Itoa(asset.ID),
-			asset.DisplayName,
-			strconv.FormatInt(asset.FileSize, 10),
-		)
-		return nil
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(asset, output.Columns{Plain: []string{"id", "name", "size"}})
 	}
 
 	// Human-friendly output
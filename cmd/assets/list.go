@@ -2,81 +2,124 @@ package assets
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var listAll bool
+
 var listCmd = &cobra.Command{
 	Use:   "list <bookmark-id>",
 	Short: "List assets for a bookmark",
 	Long:  "List all file assets attached to a specific bookmark.",
 	Example: `  clinkding assets list 42
-  clinkding assets list 42 --json`,
+  clinkding assets list 42 --json
+  clinkding assets list 42 --all`,
 	Args: cobra.ExactArgs(1),
 	RunE: runList,
 }
 
+func init() {
+	listCmd.Flags().BoolVar(&listAll, "all", false, "fetch every page")
+}
+
 func runList(cobraCmd *cobra.Command, args []string) error {
 	bookmarkID, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	assetsAPI := api.NewAssetsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
+	if listAll {
+		return listAllAssets(ctx, assetsAPI, bookmarkID, formatter)
+	}
+
 	result, err := assetsAPI.List(ctx, bookmarkID)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
+	// JSON keeps the page envelope with the total count and page links
+	if formatter.Format() == config.FormatJSON {
+		if err := formatter.Sort(result.Results); err != nil {
+			return err
+		}
 		return formatter.PrintJSON(result)
 	}
 
-	if cfg.OutputPlain {
-		for _, asset := range result.Results {
-			output.PrintPlainLine(
-				strconv.Itoa(asset.ID),
-				asset.DisplayName,
-				strconv.FormatInt(asset.FileSize, 10),
-				asset.Status,
-			)
-		}
+	if len(result.Results) == 0 && cfg.HumanOutput() {
+		formatter.Info("No assets found for bookmark #%d", bookmarkID)
 		return nil
 	}
 
-	// Human-friendly table output
-	if len(result.Results) == 0 {
+	enc := formatter.NewEncoder(assetColumns)
+	for _, asset := range result.Results {
+		if err := enc.Encode(asset); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d assets", result.Count)
+		if result.Next != nil {
+			formatter.Info("Use --all to fetch every asset")
+		}
+	}
+
+	return nil
+}
+
+// listAllAssets follows pagination to the end. Every format except the
+// table is written as each page arrives.
+func listAllAssets(ctx context.Context, assetsAPI *api.AssetsAPI, bookmarkID int, formatter *output.Formatter) error {
+	cfg := cmd.GetConfig()
+	it := assetsAPI.Iterate(bookmarkID)
+
+	enc := formatter.NewEncoder(assetColumns)
+	found := 0
+	for it.Next(ctx) {
+		if err := enc.Encode(it.Item()); err != nil {
+			return err
+		}
+		found++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if found == 0 && cfg.HumanOutput() {
 		formatter.Info("No assets found for bookmark #%d", bookmarkID)
 		return nil
 	}
-
-	table := output.NewTable([]string{"ID", "Name", "Size", "Status", "Created"})
-	for _, asset := range result.Results {
-		sizeKB := asset.FileSize / 1024
-		table.Append([]string{
-			strconv.Itoa(asset.ID),
-			asset.DisplayName,
-			fmt.Sprintf("%d KB", sizeKB),
-			asset.Status,
-			asset.DateCreated.Format("2006-01-02"),
-		})
+	if err := enc.Close(); err != nil {
+		return err
 	}
-	table.Render()
 
-	formatter.Println("")
-	formatter.Println("Total: %d assets", result.Count)
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d assets", found)
+	}
 
 	return nil
 }
+
+// assetColumns are the fields "assets list" shows by default.
+var assetColumns = output.Columns{
+	Table: []string{"id", "name", "size", "status", "created"},
+	Plain: []string{"id", "name", "size", "status"},
+}
@@ -0,0 +1,371 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/batch"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mirrorWorkers int
+	mirrorType    string
+)
+
+// mirrorManifestFile lists every mirrored asset, written last.
+const mirrorManifestFile = "manifest.json"
+
+// maxSlugLength caps the title part of bookmark directory names.
+const maxSlugLength = 60
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror <dir>",
+	Short: "Download every asset into a local directory",
+	Long: `Download the assets of every bookmark, active and archived, into dir,
+one directory per bookmark named <bookmark-id>-<title>. Use it to keep
+offline copies of the HTML snapshots linkding creates.
+
+Files that already exist with the size recorded in the manifest when they
+were downloaded are skipped, so running the command again only fetches
+new assets. A bookmark keeps its directory
+when its title changes. Assets that aren't complete on the server (still
+pending, or failed) are skipped. manifest.json in dir lists the assets
+of the last run with their bookmark and local path.`,
+	Example: `  clinkding assets mirror ~/linkding-assets
+  clinkding assets mirror ./snapshots --type snapshot --workers 8`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMirror,
+}
+
+func init() {
+	mirrorCmd.Flags().IntVar(&mirrorWorkers, "workers", 4, "number of bookmarks processed concurrently")
+	mirrorCmd.Flags().StringVar(&mirrorType, "type", "", "only mirror assets of this type (snapshot or upload)")
+}
+
+// mirrorEntry is one asset in the manifest.
+type mirrorEntry struct {
+	models.Asset
+	BookmarkURL   string `json:"bookmark_url"`
+	BookmarkTitle string `json:"bookmark_title"`
+	Path          string `json:"path,omitempty"`
+	// Download records the local file, for the next run to compare with
+	Download *downloadRecord `json:"download,omitempty"`
+	Result   string          `json:"result"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type mirrorManifest struct {
+	Source    string        `json:"source"`
+	CreatedAt time.Time     `json:"created_at"`
+	Bookmarks int           `json:"bookmarks"`
+	Assets    []mirrorEntry `json:"assets"`
+}
+
+type mirrorSummary struct {
+	Directory  string `json:"directory"`
+	Bookmarks  int    `json:"bookmarks"`
+	Downloaded int    `json:"downloaded"`
+	Present    int    `json:"present"`
+	Skipped    int    `json:"skipped"`
+	Failed     int    `json:"failed"`
+	Bytes      int64  `json:"bytes"`
+}
+
+// mirrorJob is the work for one bookmark; the worker fills in entries.
+type mirrorJob struct {
+	bookmark models.Bookmark
+	dir      string
+	entries  []mirrorEntry
+}
+
+// Results of mirroring one asset, as recorded in the manifest.
+const (
+	mirrorDownloaded = "downloaded"
+	mirrorPresent    = "present"
+	mirrorSkipped    = "skipped"
+	mirrorFailed     = "failed"
+)
+
+func runMirror(cobraCmd *cobra.Command, args []string) error {
+	root := args[0]
+	if mirrorWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+	if mirrorType != "" && mirrorType != "snapshot" && mirrorType != "upload" {
+		return exitcode.Usagef("invalid --type %q (valid: snapshot, upload)", mirrorType)
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	assetsAPI := api.NewAssetsAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput() && !cfg.Quiet
+
+	if !cfg.DryRun {
+		if err := os.MkdirAll(root, 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+	}
+	existing, err := bookmarkDirs(root)
+	if err != nil {
+		return err
+	}
+	earlier := readMirrorManifest(root)
+
+	ctx := cobraCmd.Context()
+	var jobs []*mirrorJob
+	for _, archived := range []bool{false, true} {
+		bookmarks, err := bookmarksAPI.Iterate(&api.ListOptions{Archived: archived, Limit: 100}).All(ctx)
+		if err != nil {
+			return err
+		}
+		for _, b := range bookmarks {
+			dir, ok := existing[b.ID]
+			if !ok {
+				dir = bookmarkDir(b)
+			}
+			jobs = append(jobs, &mirrorJob{bookmark: b, dir: dir})
+		}
+	}
+	if human {
+		formatter.Info("Checking the assets of %d bookmarks", len(jobs))
+	}
+
+	mirror := func(ctx context.Context, job *mirrorJob) error {
+		return mirrorBookmark(ctx, assetsAPI, root, job, earlier, cfg.DryRun)
+	}
+	progress := func(finished int, r batch.Result[*mirrorJob]) {
+		if !human {
+			return
+		}
+		if r.Err != nil {
+			formatter.Warning("[%d/%d] #%d: %v", finished, len(jobs), r.Item.bookmark.ID, r.Err)
+			return
+		}
+		for _, e := range r.Item.entries {
+			switch {
+			case e.Result == mirrorDownloaded:
+				formatter.Println("[%d/%d] %s", finished, len(jobs), e.Path)
+			case e.Result == mirrorFailed:
+				formatter.Warning("[%d/%d] %s: %s", finished, len(jobs), e.Path, e.Error)
+			case cfg.Verbose:
+				formatter.Println("[%d/%d] %s: %s", finished, len(jobs), e.Path, e.Result)
+			}
+		}
+	}
+	results := batch.Run(ctx, jobs, mirrorWorkers, mirror, progress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	manifest := mirrorManifest{Source: cfg.URL, CreatedAt: time.Now().UTC(), Bookmarks: len(jobs), Assets: []mirrorEntry{}}
+	summary := mirrorSummary{Directory: root, Bookmarks: len(jobs)}
+	listFailures := 0
+	for _, r := range results {
+		if r.Err != nil {
+			listFailures++
+			continue
+		}
+		for _, e := range r.Item.entries {
+			manifest.Assets = append(manifest.Assets, e)
+			switch e.Result {
+			case mirrorDownloaded:
+				summary.Downloaded++
+				summary.Bytes += e.FileSize
+			case mirrorPresent:
+				summary.Present++
+			case mirrorSkipped:
+				summary.Skipped++
+			case mirrorFailed:
+				summary.Failed++
+			}
+		}
+	}
+	summary.Failed += listFailures
+
+	if cfg.DryRun {
+		formatter.DryRun("would download %d assets (%d bytes) to %s; %d are already present", summary.Downloaded, summary.Bytes, root, summary.Present)
+	} else if err := writeMirrorManifest(root, manifest); err != nil {
+		return err
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		if err := formatter.PrintRecord(summary, output.Columns{Plain: []string{"downloaded", "present", "skipped", "failed"}}); err != nil {
+			return err
+		}
+	} else if !cfg.DryRun {
+		formatter.Println("")
+		formatter.Success("Mirrored %d bookmarks to %s: %d downloaded, %d already present, %d skipped, %d failed",
+			summary.Bookmarks, root, summary.Downloaded, summary.Present, summary.Skipped, summary.Failed)
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d assets or bookmarks failed; run the command again to retry them", summary.Failed)
+	}
+	return nil
+}
+
+// mirrorBookmark lists a bookmark's assets and downloads the missing ones,
+// comparing files with the entries of the earlier manifest by asset ID.
+// Failed downloads are recorded in the entries; only a failed listing
+// fails the bookmark.
+func mirrorBookmark(ctx context.Context, assetsAPI *api.AssetsAPI, root string, job *mirrorJob, earlier map[int]mirrorEntry, dryRun bool) error {
+	assets, err := assetsAPI.Iterate(job.bookmark.ID).All(ctx)
+	if err != nil {
+		return err
+	}
+	if len(assets) == 0 {
+		return nil
+	}
+
+	names := assetFileNames(assets)
+	dir := filepath.Join(root, job.dir)
+	created := false
+	for _, asset := range assets {
+		entry := mirrorEntry{
+			Asset:         asset,
+			BookmarkURL:   job.bookmark.URL,
+			BookmarkTitle: job.bookmark.Title,
+			Path:          filepath.ToSlash(filepath.Join(job.dir, names[asset.ID])),
+		}
+		path := filepath.Join(dir, names[asset.ID])
+		var record *downloadRecord
+		if e, ok := earlier[asset.ID]; ok && e.Path == entry.Path {
+			record = e.Download
+		}
+
+		switch {
+		case mirrorType != "" && asset.AssetType != mirrorType:
+			continue
+		case asset.Status != "" && asset.Status != "complete":
+			entry.Result = mirrorSkipped
+			entry.Path = ""
+		case downloaded(path, record, asset.FileSize):
+			entry.Result = mirrorPresent
+			if record == nil {
+				record = &downloadRecord{Bytes: asset.FileSize}
+			}
+			entry.Download = record
+		case dryRun:
+			entry.Result = mirrorDownloaded
+		default:
+			if !created {
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					return fmt.Errorf("failed to create directory: %w", err)
+				}
+				created = true
+			}
+			if fetched, err := downloadAsset(ctx, assetsAPI, asset, path, nil); err != nil {
+				// The earlier file, if any, is still in place
+				entry.Result = mirrorFailed
+				entry.Error = err.Error()
+				entry.Download = record
+			} else {
+				entry.Result = mirrorDownloaded
+				entry.Download = &fetched
+			}
+		}
+		job.entries = append(job.entries, entry)
+	}
+	return nil
+}
+
+// bookmarkDir names a bookmark's directory: its ID and a slug of its
+// title, or of its URL if it has no title.
+func bookmarkDir(b models.Bookmark) string {
+	source := b.Title
+	if source == "" {
+		source = strings.TrimPrefix(strings.TrimPrefix(b.URL, "https://"), "http://")
+	}
+
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(source) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteRune('-')
+			dash = true
+		}
+		if slug.Len() >= maxSlugLength {
+			break
+		}
+	}
+
+	name := strings.Trim(slug.String(), "-")
+	if name == "" {
+		return strconv.Itoa(b.ID)
+	}
+	return strconv.Itoa(b.ID) + "-" + name
+}
+
+// bookmarkDirs finds the directories of an earlier mirror by the bookmark
+// ID they start with, so renamed bookmarks keep their directory.
+func bookmarkDirs(root string) (map[int]string, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return map[int]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	dirs := make(map[int]string)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		prefix, _, _ := strings.Cut(entry.Name(), "-")
+		if id, err := strconv.Atoi(prefix); err == nil {
+			dirs[id] = entry.Name()
+		}
+	}
+	return dirs, nil
+}
+
+// readMirrorManifest reads the entries of an earlier run's manifest by
+// asset ID. A missing or unreadable manifest means no entries.
+func readMirrorManifest(root string) map[int]mirrorEntry {
+	entries := make(map[int]mirrorEntry)
+	data, err := os.ReadFile(filepath.Join(root, mirrorManifestFile))
+	if err != nil {
+		return entries
+	}
+	var manifest mirrorManifest
+	if json.Unmarshal(data, &manifest) == nil {
+		for _, e := range manifest.Assets {
+			entries[e.ID] = e
+		}
+	}
+	return entries
+}
+
+// writeMirrorManifest writes the manifest sorted by bookmark and asset.
+func writeMirrorManifest(root string, manifest mirrorManifest) error {
+	sort.SliceStable(manifest.Assets, func(i, j int) bool {
+		a, b := manifest.Assets[i], manifest.Assets[j]
+		if a.BookmarkID != b.BookmarkID {
+			return a.BookmarkID < b.BookmarkID
+		}
+		return a.ID < b.ID
+	})
+
+	return writeManifest(filepath.Join(root, mirrorManifestFile), manifest)
+}
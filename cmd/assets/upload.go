@@ -1,58 +1,172 @@
 package assets
 
 import (
-	"context"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
 	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var uploadName string
+
 var uploadCmd = &cobra.Command{
-	Use:   "upload <bookmark-id> <file-path>",
-	Short: "Upload an asset to a bookmark",
-	Long:  "Upload a file as an asset attachment to a bookmark.",
+	Use:   "upload <bookmark-id> <file>...",
+	Short: "Upload assets to a bookmark",
+	Long: `Upload files as asset attachments to a bookmark. Files are streamed to
+the server, with a progress bar when stderr is a terminal.
+
+Each argument may be a file, a directory, whose files are all uploaded
+(not those in subdirectories, nor hidden ones), or a glob pattern, quoted
+so the shell leaves it alone. Pass "-" to upload stdin, with --name giving
+the asset's name; stdin is buffered in a temporary file first, since the
+server needs to know its size. --name also renames a single file.
+
+When several files are uploaded, a failed upload doesn't stop the others.`,
 	Example: `  clinkding assets upload 42 ~/Documents/screenshot.png
-  clinkding assets upload 42 ./report.pdf`,
-	Args: cobra.ExactArgs(2),
+  clinkding assets upload 42 ./report.pdf ./notes.md
+  clinkding assets upload 42 './scans/*.pdf'
+  clinkding assets upload 42 ./attachments
+  pg_dump mydb | gzip | clinkding assets upload 42 - --name mydb.sql.gz`,
+	Args: cobra.MinimumNArgs(2),
 	RunE: runUpload,
 }
 
+func init() {
+	uploadCmd.Flags().StringVar(&uploadName, "name", "", "name of the asset, required when uploading stdin")
+}
+
+// uploadResult is the outcome of uploading one file, for JSON output.
+type uploadResult struct {
+	File  string        `json:"file"`
+	Asset *models.Asset `json:"asset,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
 func runUpload(cobraCmd *cobra.Command, args []string) error {
 	bookmarkID, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
-	filePath := args[1]
+	files, err := uploadFiles(args[1:])
+	if err != nil {
+		return err
+	}
+	stdin := false
+	for _, file := range files {
+		if file == "-" {
+			stdin = true
+		}
+	}
+	switch {
+	case stdin && len(files) > 1:
+		return exitcode.Usagef("stdin (-) can only be uploaded on its own")
+	case stdin && strings.TrimSpace(uploadName) == "":
+		return exitcode.Usagef("uploading stdin (-) requires --name")
+	case uploadName != "" && len(files) > 1:
+		return exitcode.Usagef("--name can only be used when uploading a single file")
+	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	assetsAPI := api.NewAssetsAPI(httpClient)
 	formatter := output.New(cfg)
+	human := cfg.HumanOutput() && !cfg.Quiet
+
+	ctx := cobraCmd.Context()
+	var results []uploadResult
+	failed := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		upload, cleanup, err := openUpload(file)
+		if err != nil {
+			if len(files) == 1 {
+				return err
+			}
+			failed++
+			results = append(results, uploadResult{File: file, Error: err.Error()})
+			formatter.Error("%s: %v", file, err)
+			continue
+		}
+		if uploadName != "" {
+			upload.Name = uploadName
+		}
 
-	if !cfg.Quiet {
-		formatter.Println("Uploading %s...", filePath)
+		progress := formatter.NewProgress(upload.Name, upload.Size)
+		if !cfg.DryRun {
+			upload.Progress = progress.Set
+			if human && !progress.Active() {
+				formatter.Println("Uploading %s...", file)
+			}
+		}
+		asset, err := assetsAPI.UploadFrom(ctx, bookmarkID, upload)
+		progress.Done()
+		cleanup()
+		if err != nil {
+			if len(files) == 1 {
+				return err
+			}
+			failed++
+			results = append(results, uploadResult{File: file, Error: err.Error()})
+			formatter.Error("%s: %v", file, err)
+			continue
+		}
+		results = append(results, uploadResult{File: file, Asset: asset})
+
+		switch {
+		case cfg.DryRun:
+			formatter.DryRun("would upload %s (%s) to bookmark #%d", upload.Name, output.FormatBytes(upload.Size), bookmarkID)
+		case human && len(files) > 1:
+			formatter.Success("Uploaded %s as asset #%d (%s)", upload.Name, asset.ID, output.FormatBytes(upload.Size))
+		}
 	}
 
-	ctx := context.Background()
-	asset, err := assetsAPI.Upload(ctx, bookmarkID, filePath)
-	if err != nil {
-		return err
+	if cfg.DryRun {
+		return nil
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(asset)
+	if len(files) == 1 {
+		return printUploaded(formatter, results[0].Asset)
+	}
+	if !cfg.HumanOutput() {
+		enc := formatter.NewEncoder(output.Columns{Plain: []string{"file", "error"}})
+		for _, r := range results {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	} else {
+		formatter.Println("")
+		formatter.Println("Uploaded %d of %d files to bookmark #%d", len(files)-failed, len(files), bookmarkID)
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(asset.ID), asset.DisplayName)
-		return nil
+	if failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(files))
+	}
+	return nil
+}
+
+func printUploaded(formatter *output.Formatter, asset *models.Asset) error {
+	cfg := cmd.GetConfig()
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(asset, output.Columns{Plain: []string{"id", "name"}})
 	}
 
 	// Human-friendly output
@@ -65,3 +179,84 @@ func runUpload(cobraCmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// uploadFiles expands the file arguments: directories to the files in
+// them and glob patterns to their matches, each sorted by name.
+func uploadFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if arg == "-" {
+			files = append(files, arg)
+			continue
+		}
+
+		matches := []string{arg}
+		if strings.ContainsAny(arg, "*?[") {
+			if _, err := os.Stat(arg); err != nil {
+				if matches, err = filepath.Glob(arg); err != nil {
+					return nil, exitcode.Usagef("invalid pattern %q: %v", arg, err)
+				}
+				if len(matches) == 0 {
+					return nil, exitcode.Usagef("no files match %s", arg)
+				}
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file: %w", err)
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+
+			entries, err := os.ReadDir(match)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read directory: %w", err)
+			}
+			found := false
+			for _, entry := range entries {
+				if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+					files = append(files, filepath.Join(match, entry.Name()))
+					found = true
+				}
+			}
+			if !found && len(matches) == 1 {
+				return nil, exitcode.Usagef("no files to upload in %s", match)
+			}
+		}
+	}
+	return files, nil
+}
+
+// openUpload prepares a file, or stdin for "-", for uploading. Stdin is
+// copied to a temporary file, which cleanup removes.
+func openUpload(file string) (*client.Upload, func(), error) {
+	if file != "-" {
+		upload, err := client.FileUpload(file)
+		return upload, func() {}, err
+	}
+
+	tmp, err := os.CreateTemp("", "clinkding-upload-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to buffer stdin: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(tmp.Name()) }
+	_, err = io.Copy(tmp, os.Stdin)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("failed to buffer stdin: %w", err)
+	}
+
+	upload, err := client.FileUpload(tmp.Name())
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return upload, cleanup, nil
+}
@@ -0,0 +1,255 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/archive"
+	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupAssets bool
+	backupForce  bool
+)
+
+var Cmd = &cobra.Command{
+	Use:   "backup <path>",
+	Short: "Back up the linkding instance",
+	Long: `Write a self-describing backup of the linkding instance: all bookmarks
+(active and archived), tags, bundles and the user profile, plus every asset
+file with --assets.
+
+The backup is a directory, or a gzip-compressed tarball when the path ends
+in .tar.gz or .tgz. Restore it with "clinkding restore".`,
+	Example: `  clinkding backup ./linkding-backup
+  clinkding backup linkding-$(date +%Y%m%d).tar.gz --assets`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackup,
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&backupAssets, "assets", false, "also download every asset file")
+	Cmd.Flags().BoolVarP(&backupForce, "force", "f", false, "overwrite an existing backup at path")
+}
+
+func runBackup(cobraCmd *cobra.Command, args []string) error {
+	target := args[0]
+	packed := archive.IsPacked(target)
+
+	if err := checkTarget(target, packed); err != nil {
+		return err
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	// Packed backups are assembled in a temporary directory first
+	dirPath := target
+	if packed {
+		tmp, err := os.MkdirTemp("", "clinkding-backup-")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(tmp) }()
+		dirPath = tmp
+	}
+
+	dir, err := archive.Create(dirPath)
+	if err != nil {
+		return err
+	}
+
+	ctx := cobraCmd.Context()
+	manifest := archive.Manifest{
+		CreatedAt: time.Now().UTC(),
+		SourceURL: cfg.URL,
+		Assets:    backupAssets,
+	}
+	progress := func(format string, args ...interface{}) {
+		if human {
+			formatter.Info(format, args...)
+		}
+	}
+
+	bookmarkIDs, err := backupBookmarks(ctx, httpClient, dir, &manifest.Counts)
+	if err != nil {
+		return err
+	}
+	progress("Bookmarks: %d (%d archived)", manifest.Counts.Bookmarks, manifest.Counts.Archived)
+
+	tags, err := api.NewTagsAPI(httpClient).Iterate(1000, 0).All(ctx)
+	if err != nil {
+		return err
+	}
+	if err := dir.WriteJSON(archive.TagsFile, tags); err != nil {
+		return err
+	}
+	manifest.Counts.Tags = len(tags)
+	progress("Tags: %d", len(tags))
+
+	bundles, err := api.NewBundlesAPI(httpClient).Iterate().All(ctx)
+	if err != nil {
+		return err
+	}
+	if err := dir.WriteJSON(archive.BundlesFile, bundles); err != nil {
+		return err
+	}
+	manifest.Counts.Bundles = len(bundles)
+	progress("Bundles: %d", len(bundles))
+
+	profile, err := api.NewUserAPI(httpClient).GetProfile(ctx)
+	if err != nil {
+		return err
+	}
+	if err := dir.WriteJSON(archive.ProfileFile, profile); err != nil {
+		return err
+	}
+
+	if backupAssets {
+		entries, err := backupAssetFiles(ctx, httpClient, dir, bookmarkIDs, progress)
+		if err != nil {
+			return err
+		}
+		manifest.Counts.Assets = len(entries)
+		progress("Assets: %d", len(entries))
+	}
+
+	// The manifest goes last, so an interrupted backup is never mistaken
+	// for a complete one.
+	if err := dir.WriteManifest(manifest); err != nil {
+		return err
+	}
+
+	if packed {
+		if err := archive.Pack(dir.Path, target); err != nil {
+			return err
+		}
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		result := backupResult{Path: target, Manifest: manifest}
+		return formatter.PrintRecord(result, output.Columns{Plain: []string{"path"}})
+	}
+
+	formatter.Success("Backup written to %s", target)
+	return nil
+}
+
+type backupResult struct {
+	Path     string           `json:"path"`
+	Manifest archive.Manifest `json:"manifest"`
+}
+
+// checkTarget refuses to overwrite an existing file or non-empty directory
+// unless --force is given.
+func checkTarget(target string, packed bool) error {
+	info, err := os.Stat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if backupForce {
+		if !packed && !info.IsDir() {
+			return exitcode.Usagef("%s exists and is not a directory", target)
+		}
+		return nil
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(target)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+	}
+	return exitcode.Usagef("%s already exists (use --force to overwrite)", target)
+}
+
+// backupBookmarks streams active and archived bookmarks into the backup
+// and returns their IDs for the asset pass.
+func backupBookmarks(ctx context.Context, httpClient *client.Client, dir *archive.Dir, counts *archive.Counts) ([]int, error) {
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+
+	file, err := dir.Create(archive.BookmarksFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var ids []int
+	array := output.NewJSONArray(file)
+	for _, archived := range []bool{false, true} {
+		it := bookmarksAPI.Iterate(&api.ListOptions{Limit: 1000, Archived: archived})
+		for it.Next(ctx) {
+			bookmark := it.Item()
+			if err := array.Add(bookmark); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", archive.BookmarksFile, err)
+			}
+			ids = append(ids, bookmark.ID)
+			counts.Bookmarks++
+			if archived {
+				counts.Archived++
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := array.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", archive.BookmarksFile, err)
+	}
+	return ids, file.Close()
+}
+
+func backupAssetFiles(ctx context.Context, httpClient *client.Client, dir *archive.Dir, bookmarkIDs []int, progress func(string, ...interface{})) ([]archive.AssetEntry, error) {
+	assetsAPI := api.NewAssetsAPI(httpClient)
+
+	entries := []archive.AssetEntry{}
+	for i, bookmarkID := range bookmarkIDs {
+		assets, err := assetsAPI.Iterate(bookmarkID).All(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, asset := range assets {
+			entry := archive.AssetEntry{Asset: asset, Path: archive.AssetPath(asset)}
+
+			// Create the parent directories and claim the file name
+			file, err := dir.Create(entry.Path)
+			if err != nil {
+				return nil, err
+			}
+			_ = file.Close()
+
+			if err := assetsAPI.Download(ctx, bookmarkID, asset.ID, dir.File(entry.Path)); err != nil {
+				return nil, fmt.Errorf("failed to download asset #%d of bookmark #%d: %w", asset.ID, bookmarkID, err)
+			}
+			entries = append(entries, entry)
+		}
+
+		if (i+1)%100 == 0 {
+			progress("Scanned assets of %d/%d bookmarks", i+1, len(bookmarkIDs))
+		}
+	}
+
+	if err := dir.WriteJSON(archive.AssetsFile, entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
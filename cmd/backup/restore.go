@@ -0,0 +1,315 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/archive"
+	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var restoreForce bool
+
+var RestoreCmd = &cobra.Command{
+	Use:   "restore <path>",
+	Short: "Restore a backup into the linkding instance",
+	Long: `Restore a backup written by "clinkding backup" into the configured
+linkding instance, which may be a different one than it was taken from.
+
+Restoring is additive and safe to repeat: bookmarks whose URL already
+exists are skipped, tags and bundles are only created when no tag or
+bundle with the same name exists, and asset files are only uploaded for
+bookmarks the restore created. Bookmark IDs change on the target instance;
+the mapping from old to new IDs is included in the JSON output.
+
+The user profile is included in backups for reference only; linkding does
+not allow it to be changed through the API.`,
+	Example: `  clinkding restore ./linkding-backup
+  clinkding restore linkding-20250101.tar.gz --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	RestoreCmd.Flags().BoolVarP(&restoreForce, "force", "f", false, "skip confirmation")
+}
+
+type restoreSummary struct {
+	Bookmarks restoreCounts    `json:"bookmarks"`
+	Tags      restoreCounts    `json:"tags"`
+	Bundles   restoreCounts    `json:"bundles"`
+	Assets    restoreCounts    `json:"assets"`
+	IDMap     map[int]int      `json:"id_map"`
+	Failures  []restoreFailure `json:"failures,omitempty"`
+}
+
+type restoreCounts struct {
+	Created int `json:"created"`
+	Skipped int `json:"skipped"`
+	Failed  int `json:"failed"`
+}
+
+type restoreFailure struct {
+	Item  string `json:"item"`
+	Error string `json:"error"`
+}
+
+func runRestore(cobraCmd *cobra.Command, args []string) error {
+	dir, err := archive.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dir.Close() }()
+
+	var bookmarks []models.Bookmark
+	if err := dir.ReadJSON(archive.BookmarksFile, &bookmarks); err != nil {
+		return err
+	}
+	var tags []models.Tag
+	if err := dir.ReadJSON(archive.TagsFile, &tags); err != nil {
+		return err
+	}
+	var bundles []models.Bundle
+	if err := dir.ReadJSON(archive.BundlesFile, &bundles); err != nil {
+		return err
+	}
+	var assets []archive.AssetEntry
+	if dir.Manifest.Assets {
+		if err := dir.ReadJSON(archive.AssetsFile, &assets); err != nil {
+			return err
+		}
+	}
+	// Check every asset path before changing anything on the server
+	assetFiles := make([]string, len(assets))
+	for i, entry := range assets {
+		if assetFiles[i], err = dir.AssetFile(entry.Path); err != nil {
+			return err
+		}
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	// Confirm unless --force is used
+	if !restoreForce && !cfg.DryRun && formatter.IsTTY() {
+		manifest := dir.Manifest
+		confirmed, err := cmd.Confirm(fmt.Sprintf(
+			"Restore %d bookmarks, %d tags, %d bundles and %d assets from %s (taken %s) into %s?",
+			len(bookmarks), len(tags), len(bundles), len(assets), manifest.SourceURL,
+			manifest.CreatedAt.Local().Format("2006-01-02 15:04"), cfg.URL))
+		if err != nil {
+			return err
+		}
+
+		if !confirmed {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+	}
+
+	ctx := cobraCmd.Context()
+	summary := &restoreSummary{IDMap: map[int]int{}}
+	fail := func(counts *restoreCounts, item string, err error) {
+		counts.Failed++
+		summary.Failures = append(summary.Failures, restoreFailure{Item: item, Error: err.Error()})
+		if human && !cfg.Quiet {
+			formatter.Warning("%s: %v", item, err)
+		}
+	}
+
+	if err := restoreTags(ctx, api.NewTagsAPI(httpClient), tags, summary, fail); err != nil {
+		return err
+	}
+
+	created, err := restoreBookmarks(ctx, api.NewBookmarksAPI(httpClient), bookmarks, summary, fail)
+	if err != nil {
+		return err
+	}
+
+	if err := restoreBundles(ctx, api.NewBundlesAPI(httpClient), bundles, summary, fail); err != nil {
+		return err
+	}
+
+	assetsAPI := api.NewAssetsAPI(httpClient)
+	for i, entry := range assets {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		newID, ok := created[entry.BookmarkID]
+		if !ok {
+			summary.Assets.Skipped++
+			continue
+		}
+		item := fmt.Sprintf("asset %s of bookmark #%d", entry.DisplayName, newID)
+		if _, err := assetsAPI.Upload(ctx, newID, assetFiles[i]); err != nil {
+			fail(&summary.Assets, item, err)
+			continue
+		}
+		summary.Assets.Created++
+	}
+
+	// Output based on format
+	if formatter.Format() == config.FormatPlain {
+		printPlainCounts("bookmarks", summary.Bookmarks)
+		printPlainCounts("tags", summary.Tags)
+		printPlainCounts("bundles", summary.Bundles)
+		printPlainCounts("assets", summary.Assets)
+	} else if !cfg.HumanOutput() {
+		if err := formatter.PrintRecord(summary, output.Columns{}); err != nil {
+			return err
+		}
+	} else if !cfg.Quiet {
+		formatter.Success("Restored backup from %s", dir.Manifest.SourceURL)
+		printCounts(formatter, "Bookmarks", summary.Bookmarks)
+		printCounts(formatter, "Tags", summary.Tags)
+		printCounts(formatter, "Bundles", summary.Bundles)
+		if dir.Manifest.Assets {
+			printCounts(formatter, "Assets", summary.Assets)
+		}
+	}
+	if cfg.DryRun {
+		formatter.DryRun("nothing was restored; the counts show what would have been created")
+	}
+
+	if n := len(summary.Failures); n > 0 {
+		return fmt.Errorf("%d item(s) could not be restored", n)
+	}
+	return nil
+}
+
+func printCounts(formatter *output.Formatter, label string, counts restoreCounts) {
+	formatter.Println("  %-10s %d created, %d skipped, %d failed", label+":", counts.Created, counts.Skipped, counts.Failed)
+}
+
+func printPlainCounts(kind string, counts restoreCounts) {
+	output.PrintPlainLine(
+		kind,
+		fmt.Sprintf("%d", counts.Created),
+		fmt.Sprintf("%d", counts.Skipped),
+		fmt.Sprintf("%d", counts.Failed),
+	)
+}
+
+type failFunc func(counts *restoreCounts, item string, err error)
+
+// restoreTags creates the tags missing on the target. Tag names are
+// compared case-insensitively, as linkding does.
+func restoreTags(ctx context.Context, tagsAPI *api.TagsAPI, tags []models.Tag, summary *restoreSummary, fail failFunc) error {
+	existing, err := tagsAPI.Iterate(1000, 0).All(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		names[strings.ToLower(tag.Name)] = true
+	}
+
+	for _, tag := range tags {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		key := strings.ToLower(tag.Name)
+		if names[key] {
+			summary.Tags.Skipped++
+			continue
+		}
+		if _, err := tagsAPI.Create(ctx, tag.Name); err != nil {
+			fail(&summary.Tags, "tag "+tag.Name, err)
+			continue
+		}
+		names[key] = true
+		summary.Tags.Created++
+	}
+	return nil
+}
+
+// restoreBookmarks creates the bookmarks missing on the target and records
+// every old ID's counterpart in summary.IDMap. It returns the subset of the
+// mapping for bookmarks it created.
+func restoreBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI, bookmarks []models.Bookmark, summary *restoreSummary, fail failFunc) (map[int]int, error) {
+	created := map[int]int{}
+	for _, bookmark := range bookmarks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		check, err := bookmarksAPI.Check(ctx, bookmark.URL)
+		if err != nil {
+			fail(&summary.Bookmarks, bookmark.URL, err)
+			continue
+		}
+		if check.Bookmark != nil {
+			summary.IDMap[bookmark.ID] = check.Bookmark.ID
+			summary.Bookmarks.Skipped++
+			continue
+		}
+
+		result, err := bookmarksAPI.Create(ctx, &models.BookmarkCreate{
+			URL:         bookmark.URL,
+			Title:       bookmark.Title,
+			Description: bookmark.Description,
+			Notes:       bookmark.Notes,
+			TagNames:    bookmark.TagNames,
+			IsArchived:  bookmark.IsArchived,
+			Unread:      bookmark.Unread,
+			Shared:      bookmark.Shared,
+			DateAdded:   bookmark.DateAdded,
+		})
+		if err != nil {
+			fail(&summary.Bookmarks, bookmark.URL, err)
+			continue
+		}
+		summary.IDMap[bookmark.ID] = result.ID
+		created[bookmark.ID] = result.ID
+		summary.Bookmarks.Created++
+	}
+	return created, nil
+}
+
+// restoreBundles creates the bundles missing on the target, matched by name.
+func restoreBundles(ctx context.Context, bundlesAPI *api.BundlesAPI, bundles []models.Bundle, summary *restoreSummary, fail failFunc) error {
+	existing, err := bundlesAPI.Iterate().All(ctx)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]bool, len(existing))
+	for _, bundle := range existing {
+		names[bundle.Name] = true
+	}
+
+	for _, bundle := range bundles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if names[bundle.Name] {
+			summary.Bundles.Skipped++
+			continue
+		}
+		order := bundle.Order
+		if _, err := bundlesAPI.Create(ctx, &models.BundleCreate{
+			Name:         bundle.Name,
+			Description:  bundle.Description,
+			Search:       bundle.Search,
+			AnyTags:      bundle.AnyTags,
+			AllTags:      bundle.AllTags,
+			ExcludedTags: bundle.ExcludedTags,
+			Order:        &order,
+		}); err != nil {
+			fail(&summary.Bundles, "bundle "+bundle.Name, err)
+			continue
+		}
+		names[bundle.Name] = true
+		summary.Bundles.Created++
+	}
+	return nil
+}
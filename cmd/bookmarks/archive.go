@@ -1,13 +1,11 @@
 package bookmarks
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -33,20 +31,25 @@ var unarchiveCmd = &cobra.Command{
 func runArchive(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	if err := bookmarksAPI.Archive(ctx, id); err != nil {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	if cfg.DryRun {
+		formatter.DryRun("would archive bookmark #%d", id)
+		return nil
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
 		formatter.Success("Bookmark #%d archived", id)
 	}
 
@@ -56,20 +59,25 @@ func runArchive(cobraCmd *cobra.Command, args []string) error {
 func runUnarchive(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	if err := bookmarksAPI.Unarchive(ctx, id); err != nil {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	if cfg.DryRun {
+		formatter.DryRun("would unarchive bookmark #%d", id)
+		return nil
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
 		formatter.Success("Bookmark #%d unarchived", id)
 	}
 
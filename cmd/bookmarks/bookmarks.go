@@ -13,10 +13,15 @@ var Cmd = &cobra.Command{
 func init() {
 	Cmd.AddCommand(listCmd)
 	Cmd.AddCommand(getCmd)
+	Cmd.AddCommand(searchCmd)
 	Cmd.AddCommand(checkCmd)
 	Cmd.AddCommand(createCmd)
 	Cmd.AddCommand(updateCmd)
+	Cmd.AddCommand(editCmd)
 	Cmd.AddCommand(archiveCmd)
 	Cmd.AddCommand(unarchiveCmd)
 	Cmd.AddCommand(deleteCmd)
+	Cmd.AddCommand(bulkCmd)
+	Cmd.AddCommand(importCmd)
+	Cmd.AddCommand(exportCmd)
 }
@@ -0,0 +1,349 @@
+package bookmarks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/batch"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bulkFilters bookmarkFilters
+	bulkStdin   bool
+	bulkTags    string
+	bulkWorkers int
+	bulkForce   bool
+)
+
+var bulkActionNames = []string{"archive", "unarchive", "delete", "tag", "untag", "share", "unshare", "mark-read"}
+
+// bulkActions maps each action to the verb used in messages.
+var bulkActions = map[string]string{
+	"archive":   "archived",
+	"unarchive": "unarchived",
+	"delete":    "deleted",
+	"tag":       "tagged",
+	"untag":     "untagged",
+	"share":     "shared",
+	"unshare":   "unshared",
+	"mark-read": "marked read",
+}
+
+var bulkCmd = &cobra.Command{
+	Use:   "bulk <archive|unarchive|delete|tag|untag|share|unshare|mark-read>",
+	Short: "Apply an action to many bookmarks",
+	Long: `Apply an action to every bookmark selected by the same filters as
+"bookmarks list", or to the IDs read from stdin with --stdin (one per line;
+only the first field of each line is used, so "list --plain" output works).
+
+The changes run concurrently. A failed bookmark doesn't stop the others;
+failures are reported at the end. With --dry-run, the selected bookmarks
+are listed without sending any changes.`,
+	Example: `  clinkding bookmarks bulk archive --modified-since 365d --dry-run
+  clinkding bookmarks bulk tag --tags "read-later" --query "golang"
+  clinkding bookmarks bulk untag --tags "todo" --bundle 3 --force
+  clinkding bookmarks list --query "example.com" --plain | clinkding bookmarks bulk delete --stdin --force`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: bulkActionNames,
+	RunE:      runBulk,
+}
+
+func init() {
+	bulkFilters.register(bulkCmd.Flags())
+	bulkCmd.Flags().BoolVar(&bulkStdin, "stdin", false, "read bookmark IDs from stdin instead of using filters")
+	bulkCmd.Flags().StringVar(&bulkTags, "tags", "", "tags to add or remove (comma-separated, for tag and untag)")
+	bulkCmd.Flags().IntVar(&bulkWorkers, "workers", 4, "number of concurrent requests")
+	bulkCmd.Flags().BoolVarP(&bulkForce, "force", "f", false, "skip confirmation")
+}
+
+// bulkTarget is a selected bookmark. Bookmarks read from stdin only have
+// an ID until an action needs their details.
+type bulkTarget struct {
+	ID    int    `json:"id"`
+	Title string `json:"title,omitempty"`
+	URL   string `json:"url,omitempty"`
+
+	bookmark *models.Bookmark
+}
+
+type bulkFailure struct {
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+type bulkSummary struct {
+	Action    string        `json:"action"`
+	Total     int           `json:"total"`
+	Succeeded int           `json:"succeeded"`
+	Skipped   int           `json:"skipped"`
+	Failed    int           `json:"failed"`
+	Failures  []bulkFailure `json:"failures,omitempty"`
+}
+
+func runBulk(cobraCmd *cobra.Command, args []string) error {
+	action := args[0]
+	verb, ok := bulkActions[action]
+	if !ok {
+		return exitcode.Usagef("unknown action %q (valid: %s)", action, strings.Join(bulkActionNames, ", "))
+	}
+	tags := splitTags(bulkTags)
+	if (action == "tag" || action == "untag") && len(tags) == 0 {
+		return exitcode.Usagef("%s requires --tags", action)
+	}
+	if bulkStdin == bulkFilters.isSet() {
+		return exitcode.Usagef("select bookmarks with filters (--query, --bundle, --added-since, --modified-since, --archived) or --stdin, but not both")
+	}
+	if bulkWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	// Confirmation reads stdin, so it can't share it with the ID list
+	confirm := !bulkForce && !cfg.DryRun && formatter.IsTTY()
+	if confirm && bulkStdin {
+		return exitcode.Usagef("--stdin requires --force, since stdin can't answer the confirmation")
+	}
+
+	ctx := cobraCmd.Context()
+	targets, err := bulkSelect(ctx, bookmarksAPI)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		if human {
+			formatter.Info("No bookmarks selected")
+		}
+		return nil
+	}
+
+	if cfg.DryRun {
+		formatter.DryRun("would %s %d bookmarks:", action, len(targets))
+		enc := formatter.NewEncoder(output.Columns{
+			Table: []string{"id", "title", "url"},
+			Plain: []string{"id"},
+		})
+		for _, target := range targets {
+			if err := enc.Encode(target); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+	}
+
+	if confirm {
+		confirmed, err := cmd.Confirm(fmt.Sprintf("%s %d bookmarks?", bulkActionTitle(action), len(targets)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+	}
+
+	apply := func(ctx context.Context, target bulkTarget) error {
+		return bulkApply(ctx, bookmarksAPI, action, tags, target)
+	}
+	progress := func(finished int, r batch.Result[bulkTarget]) {
+		if !human || cfg.Quiet {
+			return
+		}
+		switch {
+		case r.Err == nil:
+			formatter.Println("[%d/%d] %s #%d", finished, len(targets), verb, r.Item.ID)
+		case errors.Is(r.Err, api.ErrUnchanged):
+			if cfg.Verbose {
+				formatter.Println("[%d/%d] unchanged #%d", finished, len(targets), r.Item.ID)
+			}
+		default:
+			formatter.Warning("[%d/%d] #%d: %v", finished, len(targets), r.Item.ID, r.Err)
+		}
+	}
+	results := batch.Run(ctx, targets, bulkWorkers, apply, progress)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	summary := bulkSummary{Action: action, Total: len(results)}
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			summary.Succeeded++
+		case errors.Is(r.Err, api.ErrUnchanged):
+			summary.Skipped++
+		default:
+			summary.Failed++
+			summary.Failures = append(summary.Failures, bulkFailure{ID: r.Item.ID, Error: r.Err.Error()})
+		}
+	}
+
+	// Output based on format
+	if !human {
+		columns := output.Columns{Plain: []string{"succeeded", "skipped", "failed"}}
+		if err := formatter.PrintRecord(summary, columns); err != nil {
+			return err
+		}
+	} else {
+		formatter.Println("")
+		formatter.Success("%s: %d %s, %d unchanged, %d failed (of %d)",
+			bulkActionTitle(action), summary.Succeeded, verb, summary.Skipped, summary.Failed, summary.Total)
+		for _, failure := range summary.Failures {
+			formatter.Println("  #%d: %s", failure.ID, failure.Error)
+		}
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d bookmarks failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// bulkSelect returns the bookmarks to change. Every page is fetched before
+// anything is changed, since archiving or deleting would shift the pages
+// still to come.
+func bulkSelect(ctx context.Context, bookmarksAPI *api.BookmarksAPI) ([]bulkTarget, error) {
+	if bulkStdin {
+		ids, err := readIDs(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		targets := make([]bulkTarget, len(ids))
+		for i, id := range ids {
+			targets[i] = bulkTarget{ID: id}
+		}
+		return targets, nil
+	}
+
+	opts, err := bulkFilters.listOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.Limit = 100
+
+	bookmarks, err := bookmarksAPI.Iterate(opts).All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]bulkTarget, len(bookmarks))
+	for i := range bookmarks {
+		b := &bookmarks[i]
+		targets[i] = bulkTarget{ID: b.ID, Title: b.Title, URL: b.URL, bookmark: b}
+	}
+	return targets, nil
+}
+
+// readIDs reads bookmark IDs from the first field of each line, skipping
+// blank lines, comments and repeated IDs.
+func readIDs(r io.Reader) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, exitcode.Usagef("invalid bookmark ID on line %d: %s", line, fields[0])
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read IDs: %w", err)
+	}
+	return ids, nil
+}
+
+func bulkApply(ctx context.Context, bookmarksAPI *api.BookmarksAPI, action string, tags []string, target bulkTarget) error {
+	switch action {
+	case "archive":
+		return bookmarksAPI.Archive(ctx, target.ID)
+	case "unarchive":
+		return bookmarksAPI.Unarchive(ctx, target.ID)
+	case "delete":
+		return bookmarksAPI.Delete(ctx, target.ID)
+	case "share", "unshare":
+		shared := action == "share"
+		_, err := bookmarksAPI.Update(ctx, target.ID, &models.BookmarkUpdate{Shared: &shared})
+		return err
+	case "mark-read":
+		unread := false
+		_, err := bookmarksAPI.Update(ctx, target.ID, &models.BookmarkUpdate{Unread: &unread})
+		return err
+	}
+
+	// tag and untag merge with the bookmark's current tags
+	change := func(current []string) ([]string, bool) {
+		var result []string
+		if action == "tag" {
+			result = addTags(current, tags)
+		} else {
+			result = removeTags(current, tags)
+		}
+		return result, len(result) != len(current)
+	}
+	_, err := bookmarksAPI.MergeTags(ctx, target.ID, target.bookmark, time.Time{}, &models.BookmarkUpdate{}, change)
+	return err
+}
+
+// addTags appends the tags current doesn't already have. Tag names are
+// compared case-insensitively, as linkding does.
+func addTags(current, tags []string) []string {
+	result := append([]string{}, current...)
+	for _, tag := range tags {
+		if !containsTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// removeTags returns current without tags, compared case-insensitively.
+// The result is never nil, so removing the last tag clears them.
+func removeTags(current, tags []string) []string {
+	result := []string{}
+	for _, tag := range current {
+		if !containsTag(tags, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func bulkActionTitle(action string) string {
+	title := strings.ReplaceAll(action, "-", " ")
+	return strings.ToUpper(title[:1]) + title[1:]
+}
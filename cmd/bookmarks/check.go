@@ -1,13 +1,12 @@
 package bookmarks
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/config"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -26,22 +25,18 @@ func runCheck(cobraCmd *cobra.Command, args []string) error {
 	urlToCheck := args[0]
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	result, err := bookmarksAPI.Check(ctx, urlToCheck)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(result)
-	}
-
-	if cfg.OutputPlain {
+	if formatter.Format() == config.FormatPlain {
 		if result.Bookmark != nil {
 			output.PrintPlainLine("exists", fmt.Sprintf("%d", result.Bookmark.ID))
 		} else {
@@ -50,6 +45,10 @@ func runCheck(cobraCmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(result, output.Columns{})
+	}
+
 	// Human-friendly output
 	if result.Bookmark != nil {
 		formatter.Warning("Bookmark already exists!")
@@ -1,13 +1,10 @@
 package bookmarks
 
 import (
-	"context"
-	"strconv"
 	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
 	"github.com/daveonkels/clinkding/internal/models"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
@@ -48,7 +45,7 @@ func runCreate(cobraCmd *cobra.Command, args []string) error {
 	urlToCreate := args[0]
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
@@ -72,20 +69,20 @@ func runCreate(cobraCmd *cobra.Command, args []string) error {
 		Unread:      createUnread,
 	}
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	bookmark, err := bookmarksAPI.Create(ctx, bookmarkCreate)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bookmark)
+	if cfg.DryRun {
+		formatter.DryRun("would create bookmark for %s", bookmark.URL)
+		return nil
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(bookmark.ID), bookmark.URL, bookmark.Title)
-		return nil
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bookmark, output.Columns{Plain: []string{"id", "url", "title"}})
 	}
 
 	// Human-friendly output
@@ -1,16 +1,12 @@
 package bookmarks
 
 import (
-	"bufio"
-	"context"
 	"fmt"
-	"os"
 	"strconv"
-	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -34,15 +30,15 @@ func init() {
 func runDelete(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 
 	// Get bookmark details for confirmation message
 	bookmark, err := bookmarksAPI.Get(ctx, id)
@@ -51,18 +47,15 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm deletion unless --force is used
-	if !deleteForce && formatter.IsTTY() {
-		fmt.Printf("Delete bookmark #%d \"%s\"? [y/N]: ", bookmark.ID, bookmark.Title)
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	if !deleteForce && !cfg.DryRun && formatter.IsTTY() {
+		confirmed, err := cmd.Confirm(fmt.Sprintf("Delete bookmark #%d \"%s\"?", bookmark.ID, bookmark.Title))
 		if err != nil {
 			return err
 		}
 
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			formatter.Println("Aborted.")
-			return nil
+			return exitcode.ErrAborted
 		}
 	}
 
@@ -71,7 +64,12 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	if cfg.DryRun {
+		formatter.DryRun("would delete bookmark #%d \"%s\"", bookmark.ID, bookmark.Title)
+		return nil
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
 		formatter.Success("Bookmark #%d deleted", id)
 	}
 
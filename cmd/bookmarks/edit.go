@@ -0,0 +1,303 @@
+package bookmarks
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Edit a bookmark in your editor",
+	Long: `Open a bookmark's editable fields as YAML in $VISUAL or $EDITOR (vi, or
+notepad on Windows, if neither is set). When the editor closes, the
+changes are shown and only the changed fields are sent. If the file can't
+be parsed, the editor opens again with the error at the top. Delete
+everything in the file to cancel.
+
+If the bookmark is changed elsewhere while the editor is open, the update
+is refused and the edited file is kept.`,
+	Example: `  clinkding bookmarks edit 42
+  EDITOR="code --wait" clinkding bookmarks edit 42`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+// editableBookmark holds the fields "bookmarks edit" lets the user change.
+type editableBookmark struct {
+	URL         string   `yaml:"url"`
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Notes       string   `yaml:"notes"`
+	Tags        []string `yaml:"tags"`
+	Unread      bool     `yaml:"unread"`
+	Shared      bool     `yaml:"shared"`
+}
+
+// editErrorPrefix marks the error comment added above the user's text
+// when the file needs fixing.
+const editErrorPrefix = "# ERROR: "
+
+func runEdit(cobraCmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+
+	ctx := cobraCmd.Context()
+	bookmark, err := bookmarksAPI.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	original := editableBookmark{
+		URL:         bookmark.URL,
+		Title:       bookmark.Title,
+		Description: bookmark.Description,
+		Notes:       bookmark.Notes,
+		Tags:        bookmark.TagNames,
+		Unread:      bookmark.Unread,
+		Shared:      bookmark.Shared,
+	}
+	content, err := marshalEditable(id, original)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp("", fmt.Sprintf("clinkding-bookmark-%d-*.yaml", id))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	// The file is kept if the update fails, so the edits aren't lost
+	keep := false
+	defer func() {
+		if !keep {
+			_ = os.Remove(path)
+		}
+	}()
+
+	var edited editableBookmark
+	for {
+		if err := runEditor(path); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read edited file: %w", err)
+		}
+		if isBlank(data) {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+
+		edited, err = parseEditable(data)
+		if err == nil {
+			break
+		}
+		if err := os.WriteFile(path, withEditError(data, err), 0600); err != nil {
+			return fmt.Errorf("failed to write temporary file: %w", err)
+		}
+	}
+
+	update, diff := diffEditable(original, edited)
+	if len(diff) == 0 {
+		if cfg.HumanOutput() {
+			formatter.Info("No changes")
+		}
+		return nil
+	}
+	if cfg.HumanOutput() {
+		for _, line := range diff {
+			formatter.Println("%s", line)
+		}
+		formatter.Println("")
+	}
+
+	// Refuse to overwrite changes made while the editor was open
+	result, err := bookmarksAPI.UpdateIfUnmodified(ctx, id, bookmark.DateModified, update)
+	if err != nil {
+		keep = true
+		return fmt.Errorf("%w (your edits are saved in %s)", err, path)
+	}
+
+	if cfg.DryRun {
+		formatter.DryRun("would update bookmark #%d", id)
+		return nil
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(result, output.Columns{Plain: []string{"id", "url", "title"}})
+	}
+
+	formatter.Success("Bookmark #%d updated", id)
+	return nil
+}
+
+func marshalEditable(id int, b editableBookmark) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Editing bookmark #%d. Save and close the editor to apply your changes;\n", id)
+	fmt.Fprintf(&buf, "# only changed fields are sent. Delete everything to cancel.\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(b); err != nil {
+		return nil, fmt.Errorf("failed to encode bookmark: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode bookmark: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func parseEditable(data []byte) (editableBookmark, error) {
+	var b editableBookmark
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&b); err != nil {
+		return b, err
+	}
+
+	b.URL = strings.TrimSpace(b.URL)
+	if b.URL == "" {
+		return b, errors.New("url must not be empty")
+	}
+	for _, tag := range b.Tags {
+		if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return r == ' ' || r == '\t' }) {
+			return b, fmt.Errorf("invalid tag %q: tags can't be empty or contain whitespace", tag)
+		}
+	}
+	return b, nil
+}
+
+// withEditError replaces the error comment at the top of data with err.
+func withEditError(data []byte, err error) []byte {
+	lines := strings.Split(string(data), "\n")
+	for len(lines) > 0 && strings.HasPrefix(lines[0], editErrorPrefix) {
+		lines = lines[1:]
+	}
+
+	var header []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		header = append(header, editErrorPrefix+line)
+	}
+	return []byte(strings.Join(append(header, lines...), "\n"))
+}
+
+// isBlank reports whether data holds nothing but comments and whitespace.
+func isBlank(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// diffEditable returns an update holding only the fields that changed,
+// and the lines describing the changes.
+func diffEditable(before, after editableBookmark) (*models.BookmarkUpdate, []string) {
+	update := &models.BookmarkUpdate{}
+	var diff []string
+
+	text := func(name, old, new string) *string {
+		if old == new {
+			return nil
+		}
+		diff = append(diff, name+":")
+		diff = append(diff, diffLines("-", old)...)
+		diff = append(diff, diffLines("+", new)...)
+		return &new
+	}
+	update.URL = text("url", before.URL, after.URL)
+	update.Title = text("title", before.Title, after.Title)
+	update.Description = text("description", before.Description, after.Description)
+	update.Notes = text("notes", before.Notes, after.Notes)
+
+	if !models.SameTags(before.Tags, after.Tags) {
+		diff = append(diff, "tags:", "  - "+strings.Join(before.Tags, ", "), "  + "+strings.Join(after.Tags, ", "))
+		update.TagNames = append([]string{}, after.Tags...)
+	}
+
+	flag := func(name string, old, new bool) *bool {
+		if old == new {
+			return nil
+		}
+		diff = append(diff, fmt.Sprintf("%s:", name), fmt.Sprintf("  - %t", old), fmt.Sprintf("  + %t", new))
+		return &new
+	}
+	update.Unread = flag("unread", before.Unread, after.Unread)
+	update.Shared = flag("shared", before.Shared, after.Shared)
+
+	return update, diff
+}
+
+func diffLines(marker, value string) []string {
+	if value == "" {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(value, "\n") {
+		lines = append(lines, "  "+marker+" "+line)
+	}
+	return lines
+}
+
+// runEditor opens path in the user's editor and waits for it to exit. The
+// editor setting may include arguments, such as "code --wait".
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		if editor == "" {
+			editor = "notepad"
+		}
+		c = exec.Command("cmd", "/C", editor+` "`+path+`"`)
+	} else {
+		if editor == "" {
+			editor = "vi"
+		}
+		c = exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	}
+
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
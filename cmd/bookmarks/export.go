@@ -0,0 +1,138 @@
+package bookmarks
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/atomicfile"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/netscape"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFilters         bookmarkFilters
+	exportFormat          string
+	exportFile            string
+	exportIncludeArchived bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export bookmarks to a file",
+	Long: `Export bookmarks in the Netscape bookmark format (bookmarks.html), which
+browsers and most bookmark services can import.
+
+Every page of results is fetched; the same filters as "bookmarks list" can
+narrow the selection. Tags, descriptions, unread and shared state, and add
+dates are included.`,
+	Example: `  clinkding bookmarks export --file-format netscape -o bookmarks.html
+  clinkding bookmarks export --query "#golang" > golang.html
+  clinkding bookmarks export --include-archived -o everything.html`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportFilters.register(exportCmd.Flags())
+	exportCmd.Flags().StringVar(&exportFormat, "file-format", "netscape", "file format (netscape)")
+	exportCmd.Flags().StringVarP(&exportFile, "file", "o", "", "file to write (default: stdout)")
+	exportCmd.Flags().BoolVar(&exportIncludeArchived, "include-archived", false, "export active and archived bookmarks")
+}
+
+// exportResult is printed in the machine-readable formats after an export
+// to a file.
+type exportResult struct {
+	File      string `json:"file"`
+	Bookmarks int    `json:"bookmarks"`
+}
+
+func runExport(cobraCmd *cobra.Command, args []string) error {
+	if exportFormat != "netscape" {
+		return exitcode.Usagef("unsupported export format: %s (supported: netscape)", exportFormat)
+	}
+
+	opts, err := exportFilters.listOptions()
+	if err != nil {
+		return err
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+
+	// A file only replaces exportFile once the whole export was written
+	var out io.Writer = os.Stdout
+	var file *atomicfile.File
+	if exportFile != "" {
+		file, err = atomicfile.Create(exportFile, 0o644)
+		if err != nil {
+			return err
+		}
+		defer file.Abort()
+		out = file
+	}
+
+	// --archived selects archived bookmarks only; --include-archived adds
+	// them to the active ones.
+	passes := []bool{opts.Archived}
+	if exportIncludeArchived && !opts.Archived {
+		passes = append(passes, true)
+	}
+
+	ctx := cobraCmd.Context()
+	writer := netscape.NewWriter(out)
+	count := 0
+	for _, archived := range passes {
+		passOpts := *opts
+		passOpts.Archived = archived
+
+		it := bookmarksAPI.Iterate(&passOpts)
+		for it.Next(ctx) {
+			if err := writer.Write(exportedBookmark(it.Item())); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+			count++
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if file != nil {
+		if err := file.Commit(); err != nil {
+			return err
+		}
+	}
+
+	if exportFile != "" {
+		if !cfg.HumanOutput() {
+			return formatter.PrintRecord(exportResult{File: exportFile, Bookmarks: count}, output.Columns{})
+		}
+		formatter.Success("Exported %d bookmarks to %s", count, exportFile)
+	}
+
+	return nil
+}
+
+func exportedBookmark(bookmark models.Bookmark) netscape.Bookmark {
+	return netscape.Bookmark{
+		URL:          bookmark.URL,
+		Title:        bookmark.Title,
+		Description:  bookmark.Description,
+		Tags:         bookmark.TagNames,
+		AddDate:      bookmark.DateAdded,
+		LastModified: bookmark.DateModified,
+		Private:      !bookmark.Shared,
+		ToRead:       bookmark.Unread,
+	}
+}
@@ -0,0 +1,51 @@
+package bookmarks
+
+import (
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/spf13/pflag"
+)
+
+// bookmarkFilters holds the search filters shared by every command that
+// selects bookmarks the way "bookmarks list" does.
+type bookmarkFilters struct {
+	query         string
+	archived      bool
+	modifiedSince string
+	addedSince    string
+	bundle        int
+}
+
+func (f *bookmarkFilters) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.query, "query", "", "search query")
+	flags.BoolVar(&f.archived, "archived", false, "show archived only")
+	flags.StringVar(&f.modifiedSince, "modified-since", "", "filter by modification date (RFC3339 or relative: 24h, 7d)")
+	flags.StringVar(&f.addedSince, "added-since", "", "filter by creation date (RFC3339 or relative: 24h, 7d)")
+	flags.IntVar(&f.bundle, "bundle", 0, "filter by bundle ID")
+}
+
+// isSet reports whether any filter was given.
+func (f *bookmarkFilters) isSet() bool {
+	return f.query != "" || f.archived || f.modifiedSince != "" || f.addedSince != "" || f.bundle > 0
+}
+
+// listOptions converts the filters into API list options, resolving
+// relative dates against the current time.
+func (f *bookmarkFilters) listOptions() (*api.ListOptions, error) {
+	modifiedSince, err := parseDate(f.modifiedSince)
+	if err != nil {
+		return nil, exitcode.Usagef("invalid --modified-since: %w", err)
+	}
+	addedSince, err := parseDate(f.addedSince)
+	if err != nil {
+		return nil, exitcode.Usagef("invalid --added-since: %w", err)
+	}
+
+	return &api.ListOptions{
+		Query:         f.query,
+		Archived:      f.archived,
+		ModifiedSince: modifiedSince,
+		AddedSince:    addedSince,
+		BundleID:      f.bundle,
+	}, nil
+}
@@ -1,15 +1,13 @@
 package bookmarks
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -27,35 +25,23 @@ var getCmd = &cobra.Command{
 func runGet(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	bookmark, err := bookmarksAPI.Get(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bookmark)
-	}
-
-	if cfg.OutputPlain {
-		output.PrintPlainLine(
-			strconv.Itoa(bookmark.ID),
-			bookmark.URL,
-			bookmark.Title,
-			bookmark.Description,
-			bookmark.Notes,
-			strings.Join(bookmark.TagNames, ","),
-		)
-		return nil
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bookmark, output.Columns{Plain: []string{"id", "url", "title", "description", "notes", "tags"}})
 	}
 
 	// Human-friendly output
@@ -0,0 +1,199 @@
+package bookmarks
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/netscape"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat       string
+	importTags         string
+	importNoFolderTags bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import bookmarks from a file",
+	Long: `Import bookmarks from a browser or bookmark service export.
+
+The Netscape bookmark format (bookmarks.html) is supported. Folder names
+become tags, and the ADD_DATE, TAGS, PRIVATE and TOREAD attributes as well
+as <DD> descriptions are carried over. URLs that are already bookmarked are
+skipped.`,
+	Example: `  clinkding bookmarks import --input-format netscape bookmarks.html
+  clinkding bookmarks import bookmarks.html --tags "imported"
+  clinkding bookmarks import bookmarks.html --no-folder-tags`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "input-format", "netscape", "file format (netscape)")
+	importCmd.Flags().StringVar(&importTags, "tags", "", "comma-separated tags to add to every bookmark")
+	importCmd.Flags().BoolVar(&importNoFolderTags, "no-folder-tags", false, "don't turn folder names into tags")
+}
+
+type importFailure struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+type importSummary struct {
+	Total    int             `json:"total"`
+	Created  int             `json:"created"`
+	Skipped  int             `json:"skipped"`
+	Failed   int             `json:"failed"`
+	Failures []importFailure `json:"failures,omitempty"`
+}
+
+func runImport(cobraCmd *cobra.Command, args []string) error {
+	if importFormat != "netscape" {
+		return exitcode.Usagef("unsupported import format: %s (supported: netscape)", importFormat)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	entries, err := netscape.Parse(file)
+	if err != nil {
+		return err
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	extraTags := splitTags(importTags)
+	summary := importSummary{Total: len(entries)}
+	seen := make(map[string]bool)
+
+	ctx := cobraCmd.Context()
+	for i, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// The same URL often appears in several folders of an export
+		if seen[entry.URL] {
+			summary.Skipped++
+			continue
+		}
+		seen[entry.URL] = true
+
+		check, err := bookmarksAPI.Check(ctx, entry.URL)
+		if err != nil {
+			summary.Failed++
+			summary.Failures = append(summary.Failures, importFailure{URL: entry.URL, Error: err.Error()})
+			continue
+		}
+		if check.Bookmark != nil {
+			summary.Skipped++
+			if cfg.Verbose && human {
+				formatter.Println("[%d/%d] skipped %s (bookmark #%d)", i+1, len(entries), entry.URL, check.Bookmark.ID)
+			}
+			continue
+		}
+
+		bookmark, err := bookmarksAPI.Create(ctx, importedBookmark(entry, extraTags))
+		if err != nil {
+			summary.Failed++
+			summary.Failures = append(summary.Failures, importFailure{URL: entry.URL, Error: err.Error()})
+			if human {
+				formatter.Warning("%s: %v", entry.URL, err)
+			}
+			continue
+		}
+
+		summary.Created++
+		if cfg.Verbose && human {
+			formatter.Println("[%d/%d] created #%d %s", i+1, len(entries), bookmark.ID, entry.URL)
+		}
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		columns := output.Columns{Plain: []string{"created", "skipped", "failed"}}
+		if err := formatter.PrintRecord(summary, columns); err != nil {
+			return err
+		}
+	} else {
+		formatter.Println("")
+		formatter.Success("Import finished: %d created, %d skipped, %d failed (of %d)",
+			summary.Created, summary.Skipped, summary.Failed, summary.Total)
+		for _, failure := range summary.Failures {
+			formatter.Println("  %s: %s", failure.URL, failure.Error)
+		}
+	}
+	if cfg.DryRun {
+		formatter.DryRun("nothing was imported; the counts show what would have been created")
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d bookmarks failed to import", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+func importedBookmark(entry netscape.Bookmark, extraTags []string) *models.BookmarkCreate {
+	var tags []string
+	seen := make(map[string]bool)
+	addTag := func(tag string) {
+		key := strings.ToLower(tag)
+		if tag != "" && !seen[key] {
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, tag := range entry.Tags {
+		addTag(tagName(tag))
+	}
+	if !importNoFolderTags {
+		for _, folder := range entry.Folders {
+			addTag(tagName(folder))
+		}
+	}
+	for _, tag := range extraTags {
+		addTag(tag)
+	}
+
+	return &models.BookmarkCreate{
+		URL:         entry.URL,
+		Title:       entry.Title,
+		Description: entry.Description,
+		TagNames:    tags,
+		Unread:      entry.ToRead,
+		Shared:      !entry.Private,
+		DateAdded:   entry.AddDate,
+	}
+}
+
+// tagName turns a folder or tag name into a valid linkding tag, which may
+// not contain whitespace.
+func tagName(name string) string {
+	return strings.Join(strings.Fields(name), "-")
+}
+
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
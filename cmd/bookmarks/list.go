@@ -9,20 +9,17 @@ import (
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/config"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	listQuery         string
-	listLimit         int
-	listOffset        int
-	listArchived      bool
-	listUnarchived    bool
-	listModifiedSince string
-	listAddedSince    string
-	listBundle        int
+	listFilters    bookmarkFilters
+	listLimit      int
+	listOffset     int
+	listUnarchived bool
+	listAll        bool
 )
 
 var listCmd = &cobra.Command{
@@ -33,98 +30,127 @@ var listCmd = &cobra.Command{
   clinkding bookmarks list --query "golang"
   clinkding bookmarks list --archived
   clinkding bookmarks list --modified-since "7d"
-  clinkding bookmarks list --limit 20 --offset 40`,
+  clinkding bookmarks list --limit 20 --offset 40
+  clinkding bookmarks list --columns id,title,added --sort added:desc
+  clinkding bookmarks list --all --plain`,
 	RunE: runList,
 }
 
 func init() {
-	listCmd.Flags().StringVar(&listQuery, "query", "", "search query")
+	listFilters.register(listCmd.Flags())
 	listCmd.Flags().IntVar(&listLimit, "limit", 100, "max results")
 	listCmd.Flags().IntVar(&listOffset, "offset", 0, "skip n results")
-	listCmd.Flags().BoolVar(&listArchived, "archived", false, "show archived only")
 	listCmd.Flags().BoolVar(&listUnarchived, "unarchived", false, "show unarchived only")
-	listCmd.Flags().StringVar(&listModifiedSince, "modified-since", "", "filter by modification date (RFC3339 or relative: 24h, 7d)")
-	listCmd.Flags().StringVar(&listAddedSince, "added-since", "", "filter by creation date (RFC3339 or relative: 24h, 7d)")
-	listCmd.Flags().IntVar(&listBundle, "bundle", 0, "filter by bundle ID")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "fetch every page (--limit sets the page size)")
 }
 
 func runList(cobraCmd *cobra.Command, args []string) error {
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	// Parse date filters
-	modifiedSince, err := parseDate(listModifiedSince)
-	if err != nil {
-		return fmt.Errorf("invalid --modified-since: %w", err)
-	}
-	addedSince, err := parseDate(listAddedSince)
+	// Prepare list options
+	opts, err := listFilters.listOptions()
 	if err != nil {
-		return fmt.Errorf("invalid --added-since: %w", err)
+		return err
 	}
+	opts.Limit = listLimit
+	opts.Offset = listOffset
 
-	// Prepare list options
-	opts := &api.ListOptions{
-		Query:         listQuery,
-		Limit:         listLimit,
-		Offset:        listOffset,
-		Archived:      listArchived,
-		ModifiedSince: modifiedSince,
-		AddedSince:    addedSince,
-		BundleID:      listBundle,
+	return ListBookmarks(cobraCmd.Context(), bookmarksAPI, formatter, opts, listAll, BookmarkColumns, "No bookmarks found")
+}
+
+// ListBookmarks prints the bookmarks matching opts, one page of them or,
+// with all, every page, as "bookmarks list" does. columns are the default
+// fields, and empty is the message printed for humans when nothing
+// matches.
+func ListBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI, formatter *output.Formatter, opts *api.ListOptions, all bool, columns output.Columns, empty string) error {
+	cfg := cmd.GetConfig()
+	if all {
+		return listAllBookmarks(ctx, bookmarksAPI, formatter, opts, columns, empty)
 	}
 
-	ctx := context.Background()
 	result, err := bookmarksAPI.List(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
+	// JSON keeps the page envelope with the total count and page links
+	if formatter.Format() == config.FormatJSON {
+		if err := formatter.Sort(result.Results); err != nil {
+			return err
+		}
 		return formatter.PrintJSON(result)
 	}
 
-	if cfg.OutputPlain {
-		for _, bookmark := range result.Results {
-			output.PrintPlainLine(
-				strconv.Itoa(bookmark.ID),
-				bookmark.URL,
-				bookmark.Title,
-				strings.Join(bookmark.TagNames, ","),
-			)
-		}
+	if len(result.Results) == 0 && cfg.HumanOutput() {
+		formatter.Info("%s", empty)
 		return nil
 	}
 
-	// Human-friendly table output
-	if len(result.Results) == 0 {
-		formatter.Info("No bookmarks found")
-		return nil
+	enc := formatter.NewEncoder(columns)
+	for _, bookmark := range result.Results {
+		if err := enc.Encode(bookmark); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
 	}
 
-	table := output.NewTable([]string{"ID", "Title", "URL", "Tags", "Modified"})
-	for _, bookmark := range result.Results {
-		table.Append([]string{
-			strconv.Itoa(bookmark.ID),
-			output.TruncateString(bookmark.Title, 40),
-			output.TruncateString(bookmark.URL, 50),
-			output.FormatTags(bookmark.TagNames, 30),
-			bookmark.DateModified.Format("2006-01-02"),
-		})
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d bookmarks", result.Count)
+		if result.Next != nil {
+			formatter.Info("Use --offset %d to see more, or --all to fetch everything", opts.Offset+opts.Limit)
+		}
 	}
-	table.Render()
 
-	formatter.Println("")
-	formatter.Println("Total: %d bookmarks", result.Count)
-	if result.Next != nil {
-		formatter.Info("Use --offset %d to see more", listOffset+listLimit)
+	return nil
+}
+
+// listAllBookmarks follows pagination to the end. Every format except the
+// table, which has to size its columns, is written as each page arrives.
+func listAllBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI, formatter *output.Formatter, opts *api.ListOptions, columns output.Columns, empty string) error {
+	cfg := cmd.GetConfig()
+	it := bookmarksAPI.Iterate(opts)
+
+	enc := formatter.NewEncoder(columns)
+	found := 0
+	for it.Next(ctx) {
+		if err := enc.Encode(it.Item()); err != nil {
+			return err
+		}
+		found++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if found == 0 && cfg.HumanOutput() {
+		formatter.Info("%s", empty)
+		return nil
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d bookmarks", found)
 	}
 
 	return nil
 }
 
+// BookmarkColumns are the fields "bookmarks list" and other lists of
+// bookmarks show by default.
+var BookmarkColumns = output.Columns{
+	Table: []string{"id", "title", "url", "tags", "modified"},
+	Plain: []string{"id", "url", "title", "tags"},
+}
+
 func parseDate(dateStr string) (string, error) {
 	if dateStr == "" {
 		return "", nil
@@ -0,0 +1,290 @@
+package bookmarks
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/batch"
+	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/daveonkels/clinkding/internal/search"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchLimit    int
+	searchArchived bool
+	searchExact    bool
+	searchReindex  bool
+	searchAssets   bool
+	searchWorkers  int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search bookmarks by relevance",
+	Long: `Search bookmarks with a local full-text index, ranking them by relevance.
+
+Unlike --query on "bookmarks list", which the server matches literally,
+search weighs where words match (title first, then tags, description,
+notes and URL) and how rare they are, and tolerates typos. Every word of
+the query must match:
+
+  golang testing     both words, in any field
+  "error handling"   the words next to each other
+  kube*              words starting with "kube"
+
+The index is built on the first search from every bookmark, active and
+archived, and kept in the user cache directory. Searches after that don't
+contact the server; use --reindex to pick up changes. With --assets the
+index also holds the text of each bookmark's latest HTML snapshot, which is
+downloaded once and kept for later rebuilds.
+
+Matched words are highlighted in the table output.`,
+	Example: `  clinkding bookmarks search golang
+  clinkding bookmarks search '"error handling" golang'
+  clinkding bookmarks search 'kube*' --limit 5
+  clinkding bookmarks search postgres --reindex --assets
+  clinkding bookmarks search docker --json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().IntVar(&searchLimit, "limit", 20, "max results (0 for all)")
+	searchCmd.Flags().BoolVar(&searchArchived, "archived", false, "search archived bookmarks instead of active ones")
+	searchCmd.Flags().BoolVar(&searchExact, "exact", false, "don't match words with typos")
+	searchCmd.Flags().BoolVar(&searchReindex, "reindex", false, "rebuild the index from the server first")
+	searchCmd.Flags().BoolVar(&searchAssets, "assets", false, "index the text of HTML snapshots (kept in later rebuilds)")
+	searchCmd.Flags().IntVar(&searchWorkers, "workers", 4, "number of snapshots downloaded concurrently")
+}
+
+// searchResult is one match, as printed.
+type searchResult struct {
+	ID      int      `json:"id"`
+	Score   float64  `json:"score"`
+	Title   string   `json:"title"`
+	URL     string   `json:"url"`
+	Tags    []string `json:"tags"`
+	Matched []string `json:"matched"`
+	Snippet string   `json:"snippet"`
+}
+
+func runSearch(cobraCmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+	if strings.TrimSpace(strings.NewReplacer(`"`, "", "*", "").Replace(query)) == "" {
+		return exitcode.Usagef("the query has no words to search for")
+	}
+	if searchLimit < 0 {
+		return exitcode.Usagef("--limit can't be negative")
+	}
+	if searchWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	formatter := output.New(cfg)
+
+	path, err := search.Path(cfg.Profile)
+	if err != nil {
+		return err
+	}
+	idx, err := search.Load(path)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot text stays in the index until --assets=false drops it
+	url := strings.TrimSuffix(cfg.URL, "/")
+	assets := searchAssets
+	if idx != nil && !cobraCmd.Flags().Changed("assets") {
+		assets = idx.Assets
+	}
+
+	ctx := cobraCmd.Context()
+	if idx == nil || searchReindex || idx.URL != url || idx.Assets != assets {
+		if assets && cfg.Offline {
+			return exitcode.Usagef("indexing snapshots with --assets needs the server; drop --offline")
+		}
+		idx, err = buildIndex(ctx, api.NewBookmarksAPI(httpClient), api.NewAssetsAPI(httpClient), formatter, idx, url, assets)
+		if err != nil {
+			return err
+		}
+		if err := idx.Save(path); err != nil {
+			return err
+		}
+		if cfg.Verbose {
+			formatter.Println("Index: %s", path)
+		}
+	} else if age := time.Since(idx.BuiltAt); age > search.StaleAfter && cfg.HumanOutput() {
+		formatter.Info("The search index was built %s ago; use --reindex to include later changes", cmd.FormatAge(age))
+	}
+
+	results := idx.Search(query, search.Options{
+		Limit:  searchLimit,
+		Exact:  searchExact,
+		Filter: func(b models.Bookmark) bool { return b.IsArchived == searchArchived },
+	})
+
+	if len(results) == 0 && cfg.HumanOutput() {
+		formatter.Info("No bookmarks match %q", query)
+		return nil
+	}
+
+	// Output based on format; only the table highlights matches
+	table := formatter.Format() == config.FormatTable
+	enc := formatter.NewEncoder(output.Columns{
+		Table: []string{"id", "score", "title", "snippet"},
+		Plain: []string{"id", "score", "url", "title"},
+	})
+	for _, r := range results {
+		record := searchResult{
+			ID:      r.Bookmark.ID,
+			Score:   math.Round(r.Score*100) / 100,
+			Title:   r.Title.Text,
+			URL:     r.Bookmark.URL,
+			Tags:    r.Bookmark.TagNames,
+			Matched: r.Fields,
+			Snippet: r.Snippet.Text,
+		}
+		if record.Tags == nil {
+			record.Tags = []string{}
+		}
+		if table {
+			record.Title = r.Title.Mark(formatter.Highlight)
+			record.Snippet = r.Snippet.Mark(formatter.Highlight)
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// buildIndex indexes every bookmark, with the text of their snapshots when
+// assets is set. Snapshots already in the previous index aren't downloaded
+// again.
+func buildIndex(ctx context.Context, bookmarksAPI *api.BookmarksAPI, assetsAPI *api.AssetsAPI, formatter *output.Formatter, previous *search.Index, url string, assets bool) (*search.Index, error) {
+	cfg := cmd.GetConfig()
+	human := cfg.HumanOutput() && !cfg.Quiet
+
+	var docs []search.Document
+	for _, archived := range []bool{false, true} {
+		bookmarks, err := bookmarksAPI.Iterate(&api.ListOptions{Archived: archived, Limit: 100}).All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bookmarks {
+			docs = append(docs, search.Document{Bookmark: b})
+		}
+	}
+	if human {
+		formatter.Info("Indexing %d bookmarks", len(docs))
+	}
+	if !assets {
+		return search.Build(url, docs, false), nil
+	}
+
+	known := make(map[int]string)
+	if previous != nil && previous.URL == url {
+		known = previous.SnapshotText()
+	}
+	tmpDir, err := os.MkdirTemp("", "clinkding-search-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	jobs := make([]*search.Document, len(docs))
+	for i := range docs {
+		jobs[i] = &docs[i]
+	}
+	fetch := func(ctx context.Context, doc *search.Document) error {
+		return fetchSnapshotText(ctx, assetsAPI, doc, known, tmpDir)
+	}
+	failed := 0
+	progress := func(finished int, r batch.Result[*search.Document]) {
+		if r.Err != nil {
+			failed++
+			if human {
+				formatter.Warning("[%d/%d] #%d: %v", finished, len(jobs), r.Item.Bookmark.ID, r.Err)
+			}
+		}
+	}
+	batch.Run(ctx, jobs, searchWorkers, fetch, progress)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if failed > 0 && human {
+		formatter.Warning("The snapshots of %d bookmarks couldn't be indexed", failed)
+	}
+	return search.Build(url, docs, true), nil
+}
+
+// fetchSnapshotText fills in the text of a bookmark's latest complete
+// HTML snapshot, reusing known text by asset ID.
+func fetchSnapshotText(ctx context.Context, assetsAPI *api.AssetsAPI, doc *search.Document, known map[int]string, tmpDir string) error {
+	assets, err := assetsAPI.Iterate(doc.Bookmark.ID).All(ctx)
+	if err != nil {
+		return err
+	}
+	var snapshot *models.Asset
+	for i, a := range assets {
+		if a.AssetType != "snapshot" || (a.Status != "" && a.Status != "complete") {
+			continue
+		}
+		if snapshot == nil || a.DateCreated.After(snapshot.DateCreated) {
+			snapshot = &assets[i]
+		}
+	}
+	if snapshot == nil {
+		return nil
+	}
+	if text, ok := known[snapshot.ID]; ok {
+		doc.Content, doc.SnapshotID = text, snapshot.ID
+		return nil
+	}
+
+	path := filepath.Join(tmpDir, fmt.Sprintf("%d", snapshot.ID))
+	if err := assetsAPI.Download(ctx, doc.Bookmark.ID, snapshot.ID, path); err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	// The server may send the snapshot compressed as stored, or already
+	// decompressed, so go by the gzip magic number rather than the name
+	br := bufio.NewReader(file)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+	text, err := search.HTMLText(r)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	doc.Content, doc.SnapshotID = text, snapshot.ID
+	return nil
+}
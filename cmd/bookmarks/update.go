@@ -1,14 +1,13 @@
 package bookmarks
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/models"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
@@ -24,6 +23,8 @@ var (
 	updateRemoveTags  string
 	updateShared      string
 	updateUnread      string
+
+	updateIfUnmodifiedSince string
 )
 
 var updateCmd = &cobra.Command{
@@ -33,6 +34,7 @@ var updateCmd = &cobra.Command{
 	Example: `  clinkding bookmarks update 42 --title "New Title"
   clinkding bookmarks update 42 --add-tags "golang,tutorial"
   clinkding bookmarks update 42 --shared=true
+  clinkding bookmarks update 42 --title "New Title" --if-unmodified-since 2025-01-01T12:00:00Z
   clinkding bookmarks update 42 --new-url "https://new-url.com" --title "Updated"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUpdate,
@@ -48,38 +50,40 @@ func init() {
 	updateCmd.Flags().StringVar(&updateRemoveTags, "remove-tags", "", "remove tags (comma-separated)")
 	updateCmd.Flags().StringVar(&updateShared, "shared", "", "set shared status (true/false)")
 	updateCmd.Flags().StringVar(&updateUnread, "unread", "", "set unread status (true/false)")
+	updateCmd.Flags().StringVar(&updateIfUnmodifiedSince, "if-unmodified-since", "", "fail if the bookmark was modified after this time (RFC3339 or relative: 24h, 7d)")
 }
 
 func runUpdate(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bookmark ID: %s", args[0])
+		return exitcode.Usagef("invalid bookmark ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bookmarksAPI := api.NewBookmarksAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 
 	// Build update request
 	update := &models.BookmarkUpdate{}
 
 	if updateURL != "" {
-		update.URL = updateURL
+		update.URL = &updateURL
 	}
 	if updateTitle != "" {
-		update.Title = updateTitle
+		update.Title = &updateTitle
 	}
 	if updateDescription != "" {
-		update.Description = updateDescription
+		update.Description = &updateDescription
 	}
 	if updateNotes != "" {
-		update.Notes = updateNotes
+		update.Notes = &updateNotes
 	}
 
 	// Handle tag operations
+	var changeTags func(tags []string) ([]string, bool)
 	if updateTags != "" {
 		tags := strings.Split(updateTags, ",")
 		for i := range tags {
@@ -87,68 +91,59 @@ func runUpdate(cobraCmd *cobra.Command, args []string) error {
 		}
 		update.TagNames = tags
 	} else if updateAddTags != "" || updateRemoveTags != "" {
-		// Need to fetch current bookmark to manipulate tags
-		current, err := bookmarksAPI.Get(ctx, id)
-		if err != nil {
-			return err
-		}
-
-		tagSet := make(map[string]bool)
-		for _, tag := range current.TagNames {
-			tagSet[tag] = true
-		}
-
-		if updateAddTags != "" {
-			addTags := strings.Split(updateAddTags, ",")
-			for _, tag := range addTags {
-				tagSet[strings.TrimSpace(tag)] = true
-			}
-		}
-
-		if updateRemoveTags != "" {
-			removeTags := strings.Split(updateRemoveTags, ",")
-			for _, tag := range removeTags {
-				delete(tagSet, strings.TrimSpace(tag))
-			}
-		}
-
-		var newTags []string
-		for tag := range tagSet {
-			newTags = append(newTags, tag)
+		// Merged with the current tags when the update is sent
+		changeTags = func(tags []string) ([]string, bool) {
+			return removeTags(addTags(tags, splitTags(updateAddTags)), splitTags(updateRemoveTags)), true
 		}
-		update.TagNames = newTags
 	}
 
 	// Handle boolean flags
 	if updateShared != "" {
 		shared, err := strconv.ParseBool(updateShared)
 		if err != nil {
-			return fmt.Errorf("invalid --shared value: %s (use true or false)", updateShared)
+			return exitcode.Usagef("invalid --shared value: %s (use true or false)", updateShared)
 		}
 		update.Shared = &shared
 	}
 	if updateUnread != "" {
 		unread, err := strconv.ParseBool(updateUnread)
 		if err != nil {
-			return fmt.Errorf("invalid --unread value: %s (use true or false)", updateUnread)
+			return exitcode.Usagef("invalid --unread value: %s (use true or false)", updateUnread)
 		}
 		update.Unread = &unread
 	}
 
+	var since time.Time
+	if updateIfUnmodifiedSince != "" {
+		date, err := parseDate(updateIfUnmodifiedSince)
+		if err != nil {
+			return exitcode.Usagef("invalid --if-unmodified-since: %w", err)
+		}
+		since, _ = time.Parse(time.RFC3339, date)
+	}
+
 	// Update bookmark
-	bookmark, err := bookmarksAPI.Update(ctx, id, update)
+	var bookmark *models.Bookmark
+	switch {
+	case changeTags != nil:
+		bookmark, err = bookmarksAPI.MergeTags(ctx, id, nil, since, update, changeTags)
+	case !since.IsZero():
+		bookmark, err = bookmarksAPI.UpdateIfUnmodified(ctx, id, since, update)
+	default:
+		bookmark, err = bookmarksAPI.Update(ctx, id, update)
+	}
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bookmark)
+	if cfg.DryRun {
+		formatter.DryRun("would update bookmark #%d", id)
+		return nil
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(bookmark.ID), bookmark.URL, bookmark.Title)
-		return nil
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bookmark, output.Columns{Plain: []string{"id", "url", "title"}})
 	}
 
 	// Human-friendly output
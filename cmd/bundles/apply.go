@@ -0,0 +1,266 @@
+package bundles
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyFile  string
+	applyPrune bool
+	applyForce bool
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <file>",
+	Short: "Make the server's bundles match a YAML file",
+	Long: `Reconcile the server's bundles with a file written by "bundles export":
+bundles missing on the server are created, and bundles whose description,
+search, tags or order differ are updated. Bundles are matched by name.
+With --prune, bundles not in the file are deleted.
+
+The planned changes are printed before anything is sent, and confirmed
+unless --force is given. Use --dry-run to see the plan and the requests
+without applying them. Pass "-f -" to read the file from stdin, which
+requires --force.`,
+	Example: `  clinkding bundles apply -f bundles.yaml
+  clinkding bundles apply -f bundles.yaml --dry-run
+  clinkding bundles apply -f bundles.yaml --prune --force`,
+	Args: cobra.NoArgs,
+	RunE: runApply,
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "file", "f", "", "bundle file to apply (- for stdin)")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete bundles that aren't in the file")
+	applyCmd.Flags().BoolVar(&applyForce, "force", false, "skip confirmation")
+	_ = applyCmd.MarkFlagRequired("file")
+}
+
+// bundleChange is one planned change and, once applied, its outcome.
+type bundleChange struct {
+	Action  string   `json:"action"`
+	ID      int      `json:"id,omitempty"`
+	Name    string   `json:"name"`
+	Changes []string `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+
+	create *models.BundleCreate
+	update *models.BundleUpdate
+}
+
+// bundlePlan is the set of changes that make the server match the file.
+type bundlePlan struct {
+	changes   []*bundleChange
+	unchanged int
+	unlisted  int
+}
+
+func runApply(cobraCmd *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bundlesAPI := api.NewBundlesAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	// Confirmation reads stdin, so it can't share it with the file
+	confirm := !applyForce && !cfg.DryRun && formatter.IsTTY()
+	if confirm && applyFile == "-" {
+		return exitcode.Usagef("-f - requires --force, since stdin can't answer the confirmation")
+	}
+
+	var in io.Reader = os.Stdin
+	name := "stdin"
+	if applyFile != "-" {
+		f, err := os.Open(applyFile)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		in, name = f, applyFile
+	}
+	file, err := readBundleFile(in, name)
+	if err != nil {
+		return err
+	}
+
+	ctx := cobraCmd.Context()
+	current, err := bundlesAPI.Iterate().All(ctx)
+	if err != nil {
+		return err
+	}
+	plan, err := planBundles(current, file.Bundles, applyPrune)
+	if err != nil {
+		return err
+	}
+
+	if human {
+		printPlan(formatter, plan)
+		if plan.unlisted > 0 {
+			formatter.Info("%d bundles on the server aren't in %s; use --prune to delete them", plan.unlisted, name)
+		}
+	}
+	if len(plan.changes) == 0 {
+		if human {
+			formatter.Success("Bundles are up to date")
+		}
+		return nil
+	}
+
+	if confirm {
+		confirmed, err := cmd.Confirm("Apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+	}
+
+	failed := applyPlan(ctx, bundlesAPI, formatter, plan)
+
+	// Output based on format
+	if !human {
+		enc := formatter.NewEncoder(output.Columns{Plain: []string{"action", "id", "name", "error"}})
+		for _, change := range plan.changes {
+			if err := enc.Encode(change); err != nil {
+				return err
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d changes failed", failed, len(plan.changes))
+	}
+	return nil
+}
+
+// planBundles works out the changes that make current match specs. Creates
+// come first, then updates, then deletes, each in file or server order.
+func planBundles(current []models.Bundle, specs []bundleSpec, prune bool) (*bundlePlan, error) {
+	byName := make(map[string][]models.Bundle)
+	for _, b := range current {
+		byName[b.Name] = append(byName[b.Name], b)
+	}
+
+	plan := &bundlePlan{}
+	var creates, updates, deletes []*bundleChange
+	listed := make(map[string]bool)
+	for _, spec := range specs {
+		listed[spec.Name] = true
+		matches := byName[spec.Name]
+		switch len(matches) {
+		case 0:
+			_, changes := diffSpec(models.Bundle{}, spec)
+			creates = append(creates, &bundleChange{Action: "create", Name: spec.Name, Changes: changes, create: createOf(spec)})
+		case 1:
+			update, changes := diffSpec(matches[0], spec)
+			if len(changes) == 0 {
+				plan.unchanged++
+				continue
+			}
+			updates = append(updates, &bundleChange{Action: "update", ID: matches[0].ID, Name: spec.Name, Changes: changes, update: update})
+		default:
+			ids := make([]string, len(matches))
+			for i, b := range matches {
+				ids[i] = fmt.Sprintf("#%d", b.ID)
+			}
+			return nil, exitcode.Wrap(exitcode.Validation, fmt.Errorf("several bundles are named %q (%s); rename all but one before applying", spec.Name, strings.Join(ids, ", ")))
+		}
+	}
+
+	for _, b := range current {
+		if listed[b.Name] {
+			continue
+		}
+		if !prune {
+			plan.unlisted++
+			continue
+		}
+		deletes = append(deletes, &bundleChange{Action: "delete", ID: b.ID, Name: b.Name})
+	}
+
+	plan.changes = append(append(creates, updates...), deletes...)
+	return plan, nil
+}
+
+func printPlan(formatter *output.Formatter, plan *bundlePlan) {
+	counts := make(map[string]int)
+	for _, change := range plan.changes {
+		counts[change.Action]++
+		switch change.Action {
+		case "create":
+			formatter.Println("+ create %q", change.Name)
+		case "update":
+			formatter.Println("~ update #%d %q", change.ID, change.Name)
+		case "delete":
+			formatter.Println("- delete #%d %q", change.ID, change.Name)
+		}
+		for _, line := range change.Changes {
+			formatter.Println("    %s", line)
+		}
+	}
+	if len(plan.changes) > 0 {
+		formatter.Println("")
+	}
+	formatter.Println("Plan: %d to create, %d to update, %d to delete, %d unchanged",
+		counts["create"], counts["update"], counts["delete"], plan.unchanged)
+}
+
+// applyPlan sends each change in turn. A failed change doesn't stop the
+// others; it returns the number that failed.
+func applyPlan(ctx context.Context, bundlesAPI *api.BundlesAPI, formatter *output.Formatter, plan *bundlePlan) int {
+	cfg := cmd.GetConfig()
+	human := cfg.HumanOutput()
+
+	failed := 0
+	for _, change := range plan.changes {
+		var err error
+		switch change.Action {
+		case "create":
+			var bundle *models.Bundle
+			if bundle, err = bundlesAPI.Create(ctx, change.create); err == nil {
+				change.ID = bundle.ID
+			}
+		case "update":
+			_, err = bundlesAPI.Update(ctx, change.ID, change.update)
+		case "delete":
+			err = bundlesAPI.Delete(ctx, change.ID)
+		}
+
+		switch {
+		case err != nil:
+			failed++
+			change.Error = err.Error()
+			if human {
+				formatter.Error("Failed to %s bundle %q: %v", change.Action, change.Name, err)
+			}
+		case cfg.DryRun:
+			formatter.DryRun("would %s bundle %q", change.Action, change.Name)
+		case human:
+			formatter.Success("%s bundle #%d %q", appliedVerbs[change.Action], change.ID, change.Name)
+		}
+	}
+	return failed
+}
+
+// appliedVerbs maps each action to the word reporting it.
+var appliedVerbs = map[string]string{
+	"create": "Created",
+	"update": "Updated",
+	"delete": "Deleted",
+}
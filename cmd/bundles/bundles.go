@@ -7,7 +7,7 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "bundles",
 	Short: "Manage bundles",
-	Long:  "Commands for listing, creating, updating, and deleting bundles.",
+	Long:  "Commands for listing, creating, updating, deleting, previewing, exporting and applying bundles.",
 }
 
 func init() {
@@ -16,4 +16,7 @@ func init() {
 	Cmd.AddCommand(createCmd)
 	Cmd.AddCommand(updateCmd)
 	Cmd.AddCommand(deleteCmd)
+	Cmd.AddCommand(previewCmd)
+	Cmd.AddCommand(exportCmd)
+	Cmd.AddCommand(applyCmd)
 }
@@ -1,12 +1,8 @@
 package bundles
 
 import (
-	"context"
-	"strconv"
-
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
 	"github.com/daveonkels/clinkding/internal/models"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
@@ -14,27 +10,32 @@ import (
 
 var (
 	createDescription string
+	createCriteria    bundleCriteria
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create <name>",
 	Short: "Create a new bundle",
-	Long:  "Create a new bundle with the specified name and optional description.",
-	Example: `  clinkding bundles create "Go Resources"
-  clinkding bundles create "AI & ML" --description "AI and machine learning bookmarks"`,
+	Long: `Create a new bundle with the specified name. A bundle matches the
+bookmarks that fit its search and tag criteria; without any, it matches
+every bookmark. Check what it matches with "bundles preview".`,
+	Example: `  clinkding bundles create "Go Resources" --any-tags "golang,go"
+  clinkding bundles create "AI & ML" --description "AI and machine learning bookmarks" --search "llm" --excluded-tags "hype"
+  clinkding bundles create "Reading" --all-tags "article,to-read" --order 1`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCreate,
 }
 
 func init() {
 	createCmd.Flags().StringVar(&createDescription, "description", "", "bundle description")
+	createCriteria.register(createCmd.Flags())
 }
 
 func runCreate(cobraCmd *cobra.Command, args []string) error {
 	bundleName := args[0]
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bundlesAPI := api.NewBundlesAPI(httpClient)
 	formatter := output.New(cfg)
 
@@ -42,21 +43,22 @@ func runCreate(cobraCmd *cobra.Command, args []string) error {
 		Name:        bundleName,
 		Description: createDescription,
 	}
+	createCriteria.applyCreate(bundleCreate)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	bundle, err := bundlesAPI.Create(ctx, bundleCreate)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bundle)
+	if cfg.DryRun {
+		formatter.DryRun("would create bundle %q", bundle.Name)
+		return nil
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(bundle.ID), bundle.Name)
-		return nil
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bundle, output.Columns{Plain: []string{"id", "name"}})
 	}
 
 	// Human-friendly output
@@ -67,6 +69,7 @@ func runCreate(cobraCmd *cobra.Command, args []string) error {
 	if bundle.Description != "" {
 		formatter.Println("Description: %s", bundle.Description)
 	}
+	printCriteria(formatter, bundle)
 
 	return nil
 }
@@ -0,0 +1,81 @@
+package bundles
+
+import (
+	"strings"
+
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/pflag"
+)
+
+// bundleCriteria holds the flags that define which bookmarks a bundle
+// matches, shared by "bundles create" and "bundles update".
+type bundleCriteria struct {
+	flags        *pflag.FlagSet
+	search       string
+	anyTags      string
+	allTags      string
+	excludedTags string
+	order        int
+}
+
+func (c *bundleCriteria) register(flags *pflag.FlagSet) {
+	c.flags = flags
+	flags.StringVar(&c.search, "search", "", "search query bookmarks must match")
+	flags.StringVar(&c.anyTags, "any-tags", "", "bookmarks must have at least one of these tags (comma-separated)")
+	flags.StringVar(&c.allTags, "all-tags", "", "bookmarks must have all of these tags (comma-separated)")
+	flags.StringVar(&c.excludedTags, "excluded-tags", "", "bookmarks must have none of these tags (comma-separated)")
+	flags.IntVar(&c.order, "order", 0, "position of the bundle in linkding's bundle list")
+}
+
+func (c *bundleCriteria) applyCreate(create *models.BundleCreate) {
+	create.Search = c.search
+	create.AnyTags = tagList(c.anyTags)
+	create.AllTags = tagList(c.allTags)
+	create.ExcludedTags = tagList(c.excludedTags)
+	if c.flags.Changed("order") {
+		create.Order = &c.order
+	}
+}
+
+// applyUpdate sets the criteria given on the command line, so an empty
+// value such as --search "" clears that criterion.
+func (c *bundleCriteria) applyUpdate(update *models.BundleUpdate) {
+	text := func(name, value string) *string {
+		if !c.flags.Changed(name) {
+			return nil
+		}
+		return &value
+	}
+	update.Search = text("search", c.search)
+	update.AnyTags = text("any-tags", tagList(c.anyTags))
+	update.AllTags = text("all-tags", tagList(c.allTags))
+	update.ExcludedTags = text("excluded-tags", tagList(c.excludedTags))
+	if c.flags.Changed("order") {
+		update.Order = &c.order
+	}
+}
+
+// tagList converts a comma- or space-separated tag list to the
+// space-separated form linkding stores for bundles.
+func tagList(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}), " ")
+}
+
+// printCriteria prints what a bundle matches, skipping unset criteria.
+func printCriteria(formatter *output.Formatter, bundle *models.Bundle) {
+	if bundle.Search != "" {
+		formatter.Println("Search:        %s", bundle.Search)
+	}
+	if bundle.AnyTags != "" {
+		formatter.Println("Any tags:      %s", bundle.AnyTags)
+	}
+	if bundle.AllTags != "" {
+		formatter.Println("All tags:      %s", bundle.AllTags)
+	}
+	if bundle.ExcludedTags != "" {
+		formatter.Println("Excluded tags: %s", bundle.ExcludedTags)
+	}
+}
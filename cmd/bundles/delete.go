@@ -1,16 +1,12 @@
 package bundles
 
 import (
-	"bufio"
-	"context"
 	"fmt"
-	"os"
 	"strconv"
-	"strings"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -34,15 +30,15 @@ func init() {
 func runDelete(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bundle ID: %s", args[0])
+		return exitcode.Usagef("invalid bundle ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bundlesAPI := api.NewBundlesAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 
 	// Get bundle details for confirmation message
 	bundle, err := bundlesAPI.Get(ctx, id)
@@ -51,18 +47,15 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm deletion unless --force is used
-	if !deleteForce && formatter.IsTTY() {
-		fmt.Printf("Delete bundle #%d \"%s\"? [y/N]: ", bundle.ID, bundle.Name)
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+	if !deleteForce && !cfg.DryRun && formatter.IsTTY() {
+		confirmed, err := cmd.Confirm(fmt.Sprintf("Delete bundle #%d \"%s\"?", bundle.ID, bundle.Name))
 		if err != nil {
 			return err
 		}
 
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			formatter.Println("Aborted.")
-			return nil
+			return exitcode.ErrAborted
 		}
 	}
 
@@ -71,7 +64,12 @@ func runDelete(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if !cfg.Quiet && !cfg.OutputJSON && !cfg.OutputPlain {
+	if cfg.DryRun {
+		formatter.DryRun("would delete bundle #%d \"%s\"", bundle.ID, bundle.Name)
+		return nil
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
 		formatter.Success("Bundle #%d deleted", id)
 	}
 
@@ -0,0 +1,87 @@
+package bundles
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/atomicfile"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var exportFile string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export bundle definitions to YAML",
+	Long: `Write every bundle, with its search, tag criteria and order, to a YAML
+file that "bundles apply" can reconcile a server against. Keep the file
+in version control to review and share bundle changes.`,
+	Example: `  clinkding bundles export -o bundles.yaml
+  clinkding bundles export > bundles.yaml`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFile, "file", "o", "", "file to write (default: stdout)")
+}
+
+// exportResult is printed in the machine-readable formats after an export
+// to a file.
+type exportResult struct {
+	File    string `json:"file"`
+	Bundles int    `json:"bundles"`
+}
+
+func runExport(cobraCmd *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bundlesAPI := api.NewBundlesAPI(httpClient)
+	formatter := output.New(cfg)
+
+	ctx := cobraCmd.Context()
+	bundles, err := bundlesAPI.Iterate().All(ctx)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(bundles, func(i, j int) bool { return bundles[i].Order < bundles[j].Order })
+
+	var file bundleFile
+	for _, bundle := range bundles {
+		file.Bundles = append(file.Bundles, specOf(bundle))
+	}
+
+	// A file only replaces exportFile once the whole export was written
+	var out io.Writer = os.Stdout
+	var f *atomicfile.File
+	if exportFile != "" {
+		f, err = atomicfile.Create(exportFile, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Abort()
+		out = f
+	}
+	if err := writeBundleFile(out, file, cfg.URL); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if f != nil {
+		if err := f.Commit(); err != nil {
+			return err
+		}
+	}
+
+	if exportFile != "" {
+		if !cfg.HumanOutput() {
+			return formatter.PrintRecord(exportResult{File: exportFile, Bundles: len(bundles)}, output.Columns{})
+		}
+		formatter.Success("Exported %d bundles to %s", len(bundles), exportFile)
+	}
+
+	return nil
+}
@@ -1,14 +1,12 @@
 package bundles
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -25,42 +23,40 @@ var getCmd = &cobra.Command{
 func runGet(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bundle ID: %s", args[0])
+		return exitcode.Usagef("invalid bundle ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bundlesAPI := api.NewBundlesAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	bundle, err := bundlesAPI.Get(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bundle)
-	}
-
-	if cfg.OutputPlain {
-		output.PrintPlainLine(
-			strconv.Itoa(bundle.ID),
-			bundle.Name,
-			bundle.Description,
-		)
-		return nil
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bundle, output.Columns{Plain: []string{"id", "name", "description", "search", "any_tags", "all_tags", "excluded_tags"}})
 	}
 
 	// Human-friendly output
 	formatter.Println(formatter.Bold("Bundle #%d"), bundle.ID)
 	formatter.Println("")
-	formatter.Println("Name:        %s", bundle.Name)
+	formatter.Println("Name:          %s", bundle.Name)
 	if bundle.Description != "" {
-		formatter.Println("Description: %s", bundle.Description)
+		formatter.Println("Description:   %s", bundle.Description)
 	}
-	formatter.Println("Created:     %s", bundle.DateAdded.Format(time.RFC3339))
+	printCriteria(formatter, bundle)
+	if bundle.Search == "" && bundle.AnyTags == "" && bundle.AllTags == "" && bundle.ExcludedTags == "" {
+		formatter.Println("Matches:       every bookmark")
+	}
+	formatter.Println("Order:         %d", bundle.Order)
+	formatter.Println("Created:       %s", bundle.DateAdded.Format(time.RFC3339))
+	formatter.Println("")
+	formatter.Info("See the matching bookmarks with: clinkding bundles preview %d", bundle.ID)
 
 	return nil
 }
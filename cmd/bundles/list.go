@@ -2,71 +2,116 @@ package bundles
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/config"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
+var listAll bool
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List bundles",
 	Long:  "List all bundles.",
 	Example: `  clinkding bundles list
-  clinkding bundles list --json`,
+  clinkding bundles list --json
+  clinkding bundles list --all`,
 	RunE: runList,
 }
 
+func init() {
+	listCmd.Flags().BoolVar(&listAll, "all", false, "fetch every page")
+}
+
 func runList(cobraCmd *cobra.Command, args []string) error {
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bundlesAPI := api.NewBundlesAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
+	if listAll {
+		return listAllBundles(ctx, bundlesAPI, formatter)
+	}
+
 	result, err := bundlesAPI.List(ctx)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
+	// JSON keeps the page envelope with the total count and page links
+	if formatter.Format() == config.FormatJSON {
+		if err := formatter.Sort(result.Results); err != nil {
+			return err
+		}
 		return formatter.PrintJSON(result)
 	}
 
-	if cfg.OutputPlain {
-		for _, bundle := range result.Results {
-			output.PrintPlainLine(
-				strconv.Itoa(bundle.ID),
-				bundle.Name,
-				bundle.Description,
-			)
-		}
+	if len(result.Results) == 0 && cfg.HumanOutput() {
+		formatter.Info("No bundles found")
 		return nil
 	}
 
-	// Human-friendly table output
-	if len(result.Results) == 0 {
+	enc := formatter.NewEncoder(bundleColumns)
+	for _, bundle := range result.Results {
+		if err := enc.Encode(bundle); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d bundles", result.Count)
+		if result.Next != nil {
+			formatter.Info("Use --all to fetch every bundle")
+		}
+	}
+
+	return nil
+}
+
+// listAllBundles follows pagination to the end. Every format except the
+// table is written as each page arrives.
+func listAllBundles(ctx context.Context, bundlesAPI *api.BundlesAPI, formatter *output.Formatter) error {
+	cfg := cmd.GetConfig()
+	it := bundlesAPI.Iterate()
+
+	enc := formatter.NewEncoder(bundleColumns)
+	found := 0
+	for it.Next(ctx) {
+		if err := enc.Encode(it.Item()); err != nil {
+			return err
+		}
+		found++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if found == 0 && cfg.HumanOutput() {
 		formatter.Info("No bundles found")
 		return nil
 	}
-
-	table := output.NewTable([]string{"ID", "Name", "Description", "Created"})
-	for _, bundle := range result.Results {
-		table.Append([]string{
-			strconv.Itoa(bundle.ID),
-			bundle.Name,
-			output.TruncateString(bundle.Description, 50),
-			bundle.DateAdded.Format("2006-01-02"),
-		})
+	if err := enc.Close(); err != nil {
+		return err
 	}
-	table.Render()
 
-	formatter.Println("")
-	formatter.Println("Total: %d bundles", result.Count)
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d bundles", found)
+	}
 
 	return nil
 }
+
+// bundleColumns are the fields "bundles list" shows by default.
+var bundleColumns = output.Columns{
+	Table: []string{"id", "name", "search", "any_tags", "all_tags", "excluded_tags"},
+	Plain: []string{"id", "name", "description"},
+}
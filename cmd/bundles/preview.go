@@ -0,0 +1,65 @@
+package bundles
+
+import (
+	"strconv"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/cmd/bookmarks"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	previewLimit  int
+	previewOffset int
+	previewAll    bool
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview <id>",
+	Short: "List the bookmarks a bundle matches",
+	Long: `List the bookmarks a bundle currently matches, as linkding shows them
+when the bundle is selected. Accepts the same output options as
+"bookmarks list".`,
+	Example: `  clinkding bundles preview 3
+  clinkding bundles preview 3 --all --plain`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPreview,
+}
+
+func init() {
+	previewCmd.Flags().IntVar(&previewLimit, "limit", 100, "max results")
+	previewCmd.Flags().IntVar(&previewOffset, "offset", 0, "skip n results")
+	previewCmd.Flags().BoolVar(&previewAll, "all", false, "fetch every page (--limit sets the page size)")
+}
+
+func runPreview(cobraCmd *cobra.Command, args []string) error {
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return exitcode.Usagef("invalid bundle ID: %s", args[0])
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bundlesAPI := api.NewBundlesAPI(httpClient)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+
+	// Fetch the bundle first: linkding ignores an unknown bundle filter and
+	// would list every bookmark
+	ctx := cobraCmd.Context()
+	bundle, err := bundlesAPI.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if cfg.HumanOutput() {
+		formatter.Println(formatter.Bold("Bundle #%d: %s"), bundle.ID, bundle.Name)
+		printCriteria(formatter, bundle)
+		formatter.Println("")
+	}
+
+	opts := &api.ListOptions{BundleID: id, Limit: previewLimit, Offset: previewOffset}
+	return bookmarks.ListBookmarks(ctx, bookmarksAPI, formatter, opts, previewAll, bookmarks.BookmarkColumns, "The bundle matches no bookmarks")
+}
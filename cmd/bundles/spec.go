@@ -0,0 +1,142 @@
+package bundles
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleFile is the YAML document written by "bundles export" and read by
+// "bundles apply". Bundles are identified by name.
+type bundleFile struct {
+	Bundles []bundleSpec `yaml:"bundles"`
+}
+
+// bundleSpec is one bundle definition. Tags are lists here rather than
+// linkding's space-separated strings, so they diff well. A missing order
+// leaves the server's order alone.
+type bundleSpec struct {
+	Name         string   `yaml:"name"`
+	Description  string   `yaml:"description,omitempty"`
+	Search       string   `yaml:"search,omitempty"`
+	AnyTags      []string `yaml:"any_tags,omitempty"`
+	AllTags      []string `yaml:"all_tags,omitempty"`
+	ExcludedTags []string `yaml:"excluded_tags,omitempty"`
+	Order        *int     `yaml:"order,omitempty"`
+}
+
+func specOf(b models.Bundle) bundleSpec {
+	order := b.Order
+	return bundleSpec{
+		Name:         b.Name,
+		Description:  b.Description,
+		Search:       b.Search,
+		AnyTags:      strings.Fields(b.AnyTags),
+		AllTags:      strings.Fields(b.AllTags),
+		ExcludedTags: strings.Fields(b.ExcludedTags),
+		Order:        &order,
+	}
+}
+
+func writeBundleFile(w io.Writer, file bundleFile, source string) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Bundles exported from %s.\n", source)
+	fmt.Fprintf(&buf, "# Apply with: clinkding bundles apply -f <file>\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("failed to encode bundles: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode bundles: %w", err)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readBundleFile parses and checks a bundle file. Unknown fields are
+// rejected, so a typo doesn't silently drop a criterion.
+func readBundleFile(r io.Reader, name string) (*bundleFile, error) {
+	var file bundleFile
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && err != io.EOF {
+		return nil, exitcode.Usagef("invalid bundle file %s: %w", name, err)
+	}
+
+	seen := make(map[string]bool)
+	for i, spec := range file.Bundles {
+		if strings.TrimSpace(spec.Name) == "" {
+			return nil, exitcode.Usagef("invalid bundle file %s: bundle %d has no name", name, i+1)
+		}
+		if seen[spec.Name] {
+			return nil, exitcode.Usagef("invalid bundle file %s: bundle %q is listed twice", name, spec.Name)
+		}
+		seen[spec.Name] = true
+
+		for _, tags := range [][]string{spec.AnyTags, spec.AllTags, spec.ExcludedTags} {
+			for _, tag := range tags {
+				if tag == "" || strings.ContainsAny(tag, " \t\n") {
+					return nil, exitcode.Usagef("invalid bundle file %s: bundle %q has invalid tag %q", name, spec.Name, tag)
+				}
+			}
+		}
+	}
+	return &file, nil
+}
+
+// diffSpec compares a bundle on the server with its definition. It returns
+// the update to send, holding only the changed fields, and a line per
+// change. Tag lists are compared ignoring order.
+func diffSpec(current models.Bundle, want bundleSpec) (*models.BundleUpdate, []string) {
+	have := specOf(current)
+	update := &models.BundleUpdate{}
+	var changes []string
+
+	text := func(name, old, new string) *string {
+		if old == new {
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("%s: %q → %q", name, old, new))
+		return &new
+	}
+	update.Description = text("description", have.Description, want.Description)
+	update.Search = text("search", have.Search, want.Search)
+
+	tags := func(name string, old, new []string) *string {
+		if models.SameTags(old, new) {
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("%s: [%s] → [%s]", name, strings.Join(old, ", "), strings.Join(new, ", ")))
+		value := strings.Join(new, " ")
+		return &value
+	}
+	update.AnyTags = tags("any_tags", have.AnyTags, want.AnyTags)
+	update.AllTags = tags("all_tags", have.AllTags, want.AllTags)
+	update.ExcludedTags = tags("excluded_tags", have.ExcludedTags, want.ExcludedTags)
+
+	if want.Order != nil && *want.Order != current.Order {
+		changes = append(changes, fmt.Sprintf("order: %d → %d", current.Order, *want.Order))
+		update.Order = want.Order
+	}
+	return update, changes
+}
+
+// createOf returns the request creating the bundle a spec defines.
+func createOf(spec bundleSpec) *models.BundleCreate {
+	return &models.BundleCreate{
+		Name:         spec.Name,
+		Description:  spec.Description,
+		Search:       spec.Search,
+		AnyTags:      strings.Join(spec.AnyTags, " "),
+		AllTags:      strings.Join(spec.AllTags, " "),
+		ExcludedTags: strings.Join(spec.ExcludedTags, " "),
+		Order:        spec.Order,
+	}
+}
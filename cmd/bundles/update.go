@@ -1,13 +1,11 @@
 package bundles
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/models"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
@@ -16,14 +14,18 @@ import (
 var (
 	updateName        string
 	updateDescription string
+	updateCriteria    bundleCriteria
 )
 
 var updateCmd = &cobra.Command{
 	Use:   "update <id>",
 	Short: "Update a bundle",
-	Long:  "Update bundle name and/or description. Only specified fields will be updated.",
+	Long: `Update a bundle's name, description or criteria. Only specified fields
+will be updated; pass an empty value, such as --search "", to clear a
+criterion.`,
 	Example: `  clinkding bundles update 42 --name "New Name"
   clinkding bundles update 42 --description "Updated description"
+  clinkding bundles update 42 --any-tags "golang,go" --search ""
   clinkding bundles update 42 --name "Go Lang" --description "Everything Go"`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUpdate,
@@ -32,38 +34,40 @@ var updateCmd = &cobra.Command{
 func init() {
 	updateCmd.Flags().StringVar(&updateName, "name", "", "new bundle name")
 	updateCmd.Flags().StringVar(&updateDescription, "description", "", "new description")
+	updateCriteria.register(updateCmd.Flags())
 }
 
 func runUpdate(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid bundle ID: %s", args[0])
+		return exitcode.Usagef("invalid bundle ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	bundlesAPI := api.NewBundlesAPI(httpClient)
 	formatter := output.New(cfg)
 
-	update := &models.BundleUpdate{
-		Name:        updateName,
-		Description: updateDescription,
+	update := &models.BundleUpdate{Name: updateName}
+	if updateDescription != "" {
+		update.Description = &updateDescription
 	}
+	updateCriteria.applyUpdate(update)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	bundle, err := bundlesAPI.Update(ctx, id, update)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(bundle)
+	if cfg.DryRun {
+		formatter.DryRun("would update bundle #%d", id)
+		return nil
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(bundle.ID), bundle.Name)
-		return nil
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(bundle, output.Columns{Plain: []string{"id", "name"}})
 	}
 
 	// Human-friendly output
@@ -74,6 +78,7 @@ func runUpdate(cobraCmd *cobra.Command, args []string) error {
 	if bundle.Description != "" {
 		formatter.Println("Description: %s", bundle.Description)
 	}
+	printCriteria(formatter, bundle)
 
 	return nil
 }
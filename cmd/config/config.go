@@ -7,11 +7,14 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage clinkding configuration",
-	Long:  "Commands for initializing, viewing, and testing clinkding configuration.",
+	Long:  "Commands for initializing, viewing, and testing clinkding configuration, and for managing named profiles.",
 }
 
 func init() {
 	Cmd.AddCommand(initCmd)
 	Cmd.AddCommand(showCmd)
 	Cmd.AddCommand(testCmd)
+	Cmd.AddCommand(listProfilesCmd)
+	Cmd.AddCommand(useProfileCmd)
+	Cmd.AddCommand(addProfileCmd)
 }
@@ -1,31 +1,36 @@
 package config
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"strings"
 
+	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize configuration file",
-	Long:  "Create a new configuration file with prompts for URL and API token.",
-	RunE:  runInit,
+	Long: `Create a new configuration file with prompts for URL and API token.
+If the file exists, only its URL and token are replaced; profiles and
+other settings are kept.
+
+The token can be stored in the system keyring (Secret Service, macOS
+Keychain or Windows Credential Manager) instead of the config file. The
+config file is always written readable by its owner only.`,
+	RunE: runInit,
 }
 
-func runInit(cmd *cobra.Command, args []string) error {
-	// Ensure config directory exists
-	_, err := config.EnsureConfigDir()
-	if err != nil {
-		return err
-	}
+var initKeyring bool
 
-	configPath, err := config.GetConfigPath()
+func init() {
+	initCmd.Flags().BoolVar(&initKeyring, "keyring", false, "store the token in the system keyring without asking")
+}
+
+func runInit(cmdCobra *cobra.Command, args []string) error {
+	configPath, err := cmd.ConfigPath()
 	if err != nil {
 		return err
 	}
@@ -33,63 +38,71 @@ func runInit(cmd *cobra.Command, args []string) error {
 	// Check if config file already exists
 	if _, err := os.Stat(configPath); err == nil {
 		fmt.Printf("Config file already exists at: %s\n", configPath)
-		fmt.Print("Overwrite? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		confirmed, err := cmd.Confirm("Replace its URL and token? Profiles and other settings are kept.")
 		if err != nil {
 			return err
 		}
 
-		response = strings.ToLower(strings.TrimSpace(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			fmt.Println("Aborted.")
-			return nil
+			return exitcode.ErrAborted
 		}
 	}
 
 	// Prompt for configuration
-	reader := bufio.NewReader(os.Stdin)
-
 	fmt.Println("Configure clinkding CLI")
 	fmt.Println()
 
-	fmt.Print("Linkding URL: ")
-	url, err := reader.ReadString('\n')
+	url, err := cmd.Prompt("Linkding URL: ")
 	if err != nil {
 		return err
 	}
-	url = strings.TrimSpace(url)
 
-	fmt.Print("API Token: ")
-	token, err := reader.ReadString('\n')
+	token, err := cmd.Prompt("API Token: ")
 	if err != nil {
 		return err
 	}
-	token = strings.TrimSpace(token)
-
-	// Create config structure
-	cfg := map[string]interface{}{
-		"url":   url,
-		"token": token,
-		"defaults": map[string]interface{}{
-			"bookmark_limit": 100,
-			"output_format":  "auto",
-		},
+
+	useKeyring := initKeyring
+	if !useKeyring {
+		useKeyring, err = cmd.Confirm("Store the token in the system keyring instead of the config file?")
+		if err != nil {
+			return err
+		}
 	}
 
-	// Write config file
-	file, err := os.Create(configPath)
+	// Keep everything else in the file, such as profiles added with
+	// add-profile and the current profile
+	settings, err := config.ReadFile(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to create config file: %w", err)
+		return err
 	}
-	defer func() { _ = file.Close() }()
-
-	encoder := yaml.NewEncoder(file)
-	defer func() { _ = encoder.Close() }()
+	settings["url"] = url
+	if useKeyring {
+		if err := config.KeyringSet(config.KeyringAccount(""), token); err != nil {
+			return err
+		}
+		delete(settings, "token")
+		settings["token_keyring"] = true
+		fmt.Println("\n✓ Token stored in the system keyring")
+	} else {
+		settings["token"] = token
+		delete(settings, "token_keyring")
+	}
+	defaults, _ := settings["defaults"].(map[string]interface{})
+	if defaults == nil {
+		defaults = map[string]interface{}{}
+	}
+	for key, value := range map[string]interface{}{"bookmark_limit": 100, "output_format": "auto"} {
+		if _, ok := defaults[key]; !ok {
+			defaults[key] = value
+		}
+	}
+	settings["defaults"] = defaults
 
-	if err := encoder.Encode(cfg); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	// Write config file
+	if err := config.WriteFile(configPath, settings); err != nil {
+		return err
 	}
 
 	fmt.Printf("\n✓ Configuration saved to: %s\n", configPath)
@@ -0,0 +1,234 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	addProfileUse     bool
+	addProfileKeyring bool
+)
+
+var listProfilesCmd = &cobra.Command{
+	Use:   "list-profiles",
+	Short: "List configured profiles",
+	Long:  "List the named profiles in the config file. The active profile is marked with *.",
+	Args:  cobra.NoArgs,
+	RunE:  runListProfiles,
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:   "use-profile <name>",
+	Short: "Set the default profile",
+	Long: `Make a profile the default by setting current_profile in the config file.
+The --profile flag and LINKDING_PROFILE still take precedence.`,
+	Example: `  clinkding config use-profile work`,
+	Args:    cobra.ExactArgs(1),
+	RunE:    runUseProfile,
+}
+
+var addProfileCmd = &cobra.Command{
+	Use:   "add-profile <name>",
+	Short: "Add or replace a profile",
+	Long: `Add a named profile to the config file, replacing any profile with the
+same name. The URL and token are taken from --url and --token, or prompted
+for when not given. With --keyring the token is stored in the system
+keyring instead of the config file. Profile names are case-insensitive.`,
+	Example: `  clinkding config add-profile work --url https://links.example.com --token abc123
+  clinkding config add-profile staging --use`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAddProfile,
+}
+
+func init() {
+	addProfileCmd.Flags().BoolVar(&addProfileUse, "use", false, "also make it the default profile")
+	addProfileCmd.Flags().BoolVar(&addProfileKeyring, "keyring", false, "store the token in the system keyring")
+}
+
+type profileInfo struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+}
+
+func runListProfiles(cmdCobra *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	formatter := output.New(cfg)
+
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]profileInfo, 0, len(names))
+	for _, name := range names {
+		profiles = append(profiles, profileInfo{
+			Name:   name,
+			URL:    cfg.Profiles[name].URL,
+			Active: name == cfg.Profile,
+		})
+	}
+
+	// Output based on format
+	if formatter.Format() == config.FormatPlain {
+		for _, p := range profiles {
+			active := ""
+			if p.Active {
+				active = "*"
+			}
+			output.PrintPlainLine(p.Name, p.URL, active)
+		}
+		return nil
+	}
+
+	if !cfg.HumanOutput() {
+		enc := formatter.NewEncoder(output.Columns{})
+		for _, p := range profiles {
+			if err := enc.Encode(p); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+	}
+
+	if len(profiles) == 0 {
+		formatter.Println("No profiles configured. Add one with: clinkding config add-profile <name>")
+		return nil
+	}
+
+	table := output.NewTable([]string{"", "NAME", "URL"})
+	for _, p := range profiles {
+		active := ""
+		if p.Active {
+			active = "*"
+		}
+		table.Append([]string{active, p.Name, p.URL})
+	}
+	table.Render()
+
+	if cfg.MissingProfile() {
+		formatter.Warning("Selected profile %q (from %s) does not exist", cfg.Profile, describeSource(cfg, "profile"))
+	}
+	return nil
+}
+
+func runUseProfile(cmdCobra *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	formatter := output.New(cfg)
+
+	name := strings.ToLower(args[0])
+	if _, ok := cfg.Profiles[name]; !ok {
+		return exitcode.Usagef("profile %q not found. Run: clinkding config list-profiles", name)
+	}
+
+	configPath, err := cmd.ConfigPath()
+	if err != nil {
+		return err
+	}
+	settings, err := config.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	settings["current_profile"] = name
+	if err := config.WriteFile(configPath, settings); err != nil {
+		return err
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
+		formatter.Success("Now using profile %q", name)
+	}
+	return nil
+}
+
+func runAddProfile(cmdCobra *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	formatter := output.New(cfg)
+
+	name := strings.ToLower(args[0])
+	if strings.ContainsAny(name, ". \t") {
+		return exitcode.Usagef("invalid profile name %q: must not contain dots or whitespace", args[0])
+	}
+
+	// --url and --token are the global flags; prompt for whatever is missing
+	url, _ := cmdCobra.Flags().GetString("url")
+	token, _ := cmdCobra.Flags().GetString("token")
+
+	var err error
+	if url == "" {
+		if url, err = cmd.Prompt("Linkding URL: "); err != nil {
+			return err
+		}
+	}
+	if token == "" {
+		if token, err = cmd.Prompt("API Token: "); err != nil {
+			return err
+		}
+	}
+	if url == "" || token == "" {
+		return exitcode.Usagef("both a URL and an API token are required")
+	}
+
+	configPath, err := cmd.ConfigPath()
+	if err != nil {
+		return err
+	}
+	settings, err := config.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	profile := config.Profile{URL: url, Token: token}
+	if addProfileKeyring {
+		if err := config.KeyringSet(config.KeyringAccount(name), token); err != nil {
+			return err
+		}
+		profile.Token = ""
+		profile.Keyring = true
+	}
+
+	_, replaced := cfg.Profiles[name]
+	config.SetProfile(settings, name, profile)
+	if addProfileUse {
+		settings["current_profile"] = name
+	}
+	if err := config.WriteFile(configPath, settings); err != nil {
+		return err
+	}
+
+	if !cfg.Quiet && cfg.HumanOutput() {
+		verb := "added"
+		if replaced {
+			verb = "replaced"
+		}
+		formatter.Success("Profile %q %s in %s", name, verb, configPath)
+		if !addProfileUse {
+			formatter.Println("Use it with --profile %s or: clinkding config use-profile %s", name, name)
+		}
+	}
+	return nil
+}
+
+// describeSource returns a human-readable origin for a setting.
+func describeSource(cfg *config.Config, key string) string {
+	switch cfg.Sources[key] {
+	case config.SourceFlag:
+		return "--" + key + " flag"
+	case config.SourceEnv:
+		return "LINKDING_" + strings.ToUpper(key)
+	case config.SourceProfile:
+		return fmt.Sprintf("profile %q", cfg.Profile)
+	case "":
+		return config.SourceUnset
+	}
+	return cfg.Sources[key]
+}
@@ -21,24 +21,42 @@ func runShow(cmdCobra *cobra.Command, args []string) error {
 	if cfg == nil {
 		// Load config manually if not loaded
 		var err error
-		cfg, err = config.Load("")
+		cfg, err = config.Load("", "")
 		if err != nil {
 			return err
 		}
 	}
 
-	configPath, _ := config.GetConfigPath()
+	configPath := cfg.File
+	if configPath == "" {
+		configPath, _ = cmd.ConfigPath()
+		configPath += " (not found)"
+	}
+
+	profile := "(none)"
+	if cfg.Profile != "" {
+		profile = fmt.Sprintf("%s (from %s)", cfg.Profile, describeSource(cfg, "profile"))
+		if cfg.MissingProfile() {
+			profile += " [not defined]"
+		}
+	}
 
 	fmt.Println("Configuration:")
 	fmt.Printf("  Config file: %s\n", configPath)
-	fmt.Printf("  URL: %s\n", cfg.URL)
-	fmt.Printf("  Token: %s\n", redactToken(cfg.Token))
+	fmt.Printf("  Profile: %s\n", profile)
+	fmt.Printf("  URL: %s (from %s)\n", valueOrNone(cfg.URL), describeSource(cfg, "url"))
+	if err := cfg.ResolveToken(); err != nil {
+		fmt.Printf("  Token: (error: %v)\n", err)
+	} else {
+		fmt.Printf("  Token: %s (from %s)\n", redactToken(cfg.Token), describeSource(cfg, "token"))
+	}
 	fmt.Printf("  Default bookmark limit: %d\n", cfg.Defaults.BookmarkLimit)
 	fmt.Printf("  Default output format: %s\n", cfg.Defaults.OutputFormat)
 	fmt.Println()
 	fmt.Println("Environment variables:")
 	fmt.Println("  LINKDING_URL:", getEnvOrNone("LINKDING_URL"))
-	fmt.Println("  LINKDING_TOKEN:", redactToken(getEnvOrNone("LINKDING_TOKEN")))
+	fmt.Println("  LINKDING_TOKEN:", redactToken(os.Getenv("LINKDING_TOKEN")))
+	fmt.Println("  LINKDING_PROFILE:", getEnvOrNone("LINKDING_PROFILE"))
 	fmt.Println("  NO_COLOR:", getEnvOrNone("NO_COLOR"))
 
 	return nil
@@ -55,7 +73,11 @@ func redactToken(token string) string {
 }
 
 func getEnvOrNone(key string) string {
-	if val := os.Getenv(key); val != "" {
+	return valueOrNone(os.Getenv(key))
+}
+
+func valueOrNone(val string) string {
+	if val != "" {
 		return val
 	}
 	return "(not set)"
@@ -1,12 +1,11 @@
 package config
 
 import (
-	"context"
 	"fmt"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +22,13 @@ func runTest(cmdCobra *cobra.Command, args []string) error {
 		return fmt.Errorf("configuration not loaded")
 	}
 
+	if cfg.MissingProfile() {
+		return exitcode.Configf("profile %q not found", cfg.Profile)
+	}
+	if err := cfg.ResolveToken(); err != nil {
+		return exitcode.Wrap(exitcode.Config, err)
+	}
+
 	if cfg.URL == "" {
 		return fmt.Errorf("linkding URL not configured")
 	}
@@ -33,11 +39,11 @@ func runTest(cmdCobra *cobra.Command, args []string) error {
 	fmt.Printf("Testing connection to %s...\n", cfg.URL)
 
 	// Create client and API
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	userAPI := api.NewUserAPI(httpClient)
 
 	// Fetch user profile
-	ctx := context.Background()
+	ctx := cmdCobra.Context()
 	profile, err := userAPI.GetProfile(ctx)
 	if err != nil {
 		return fmt.Errorf("connection test failed: %w", err)
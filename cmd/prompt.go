@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdin is shared by every prompt so buffered input isn't lost between
+// questions when answers are piped in.
+var stdin = bufio.NewReader(os.Stdin)
+
+// Prompt prints question and returns the trimmed line the user typed.
+func Prompt(question string) (string, error) {
+	fmt.Print(question)
+	response, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || response == "") {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// Confirm asks a yes/no question. Anything but "y" or "yes" counts as no.
+func Confirm(question string) (bool, error) {
+	response, err := Prompt(question + " [y/N]: ")
+	if err != nil {
+		return false, err
+	}
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes", nil
+}
@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/daveonkels/clinkding/internal/client"
 	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/mirror"
+	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -11,18 +20,33 @@ var (
 	cfgFile     string
 	url         string
 	token       string
+	profile     string
 	outputJSON  bool
 	outputPlain bool
+	outputName  string
+	format      string
+	columns     []string
+	sortBy      string
 	noColor     bool
 	quiet       bool
 	verbose     bool
+	dryRun      bool
+	offline     bool
 
-	cfg     *config.Config
-	version string
-	commit  string
-	date    string
+	retryAttempts int
+	retryMaxWait  time.Duration
+
+	cfg           *config.Config
+	offlineMirror *mirror.Mirror
+	version       string
+	commit        string
+	date          string
 )
 
+// OnlineAnnotation marks a command that always talks to the server, so
+// --offline is refused rather than answered from the mirror.
+const OnlineAnnotation = "clinkding/online"
+
 var rootCmd = &cobra.Command{
 	Use:   "clinkding",
 	Short: "Modern CLI for linkding bookmark manager",
@@ -41,33 +65,64 @@ Features:
 		}
 
 		var err error
-		cfg, err = config.Load(cfgFile)
+		cfg, err = config.Load(cfgFile, profile)
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return exitcode.Configf("failed to load config: %w", err)
 		}
 
 		// Override config with flags if provided
 		if url != "" {
-			cfg.URL = url
+			cfg.URL, cfg.Sources["url"] = url, config.SourceFlag
 		}
 		if token != "" {
-			cfg.Token = token
+			cfg.Token, cfg.Sources["token"] = token, config.SourceFlag
+		}
+		if cmd.Flags().Changed("retry-attempts") {
+			cfg.Retry.MaxAttempts = retryAttempts
+		}
+		if cmd.Flags().Changed("retry-max-wait") {
+			cfg.Retry.MaxWait = retryMaxWait
 		}
 
 		// Set output preferences
-		cfg.OutputJSON = outputJSON
-		cfg.OutputPlain = outputPlain
+		outputChanged := cmd.Root().PersistentFlags().Changed("output")
+		if err := resolveOutput(cfg, outputChanged); err != nil {
+			return err
+		}
+		if _, err := output.ParseSort(sortBy); err != nil {
+			return err
+		}
+		cfg.Sort = sortBy
+		for _, name := range columns {
+			cfg.Columns = append(cfg.Columns, strings.TrimSpace(name))
+		}
 		cfg.NoColor = noColor
 		cfg.Quiet = quiet
 		cfg.Verbose = verbose
+		cfg.DryRun = dryRun
+		cfg.Offline = offline
 
 		// Validate required config (except for config commands)
 		if cmd.Parent() != nil && cmd.Parent().Name() != "config" {
+			if cfg.MissingProfile() {
+				return exitcode.Configf("profile %q not found. Run: clinkding config list-profiles", cfg.Profile)
+			}
+			if err := cfg.ResolveToken(); err != nil {
+				return exitcode.Wrap(exitcode.Config, err)
+			}
 			if cfg.URL == "" {
-				return fmt.Errorf("linkding URL not configured. Use --url flag or run: clinkding config init")
+				return exitcode.Configf("linkding URL not configured. Use --url flag or run: clinkding config init")
 			}
-			if cfg.Token == "" {
-				return fmt.Errorf("API token not configured. Use --token flag or run: clinkding config init")
+			// The mirror answers offline requests without a token
+			if cfg.Token == "" && !cfg.Offline {
+				return exitcode.Configf("API token not configured. Use --token flag or run: clinkding config init")
+			}
+
+			if cfg.Offline {
+				if _, ok := cmd.Annotations[OnlineAnnotation]; ok {
+					return exitcode.Usagef("%s needs the server and can't be used with --offline", cmd.CommandPath())
+				}
+				return openMirror(cfg)
 			}
 		}
 
@@ -78,21 +133,116 @@ Features:
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	return ExecuteContext(context.Background())
+}
+
+// ExecuteContext runs the root command with ctx, which commands receive via
+// cobra.Command.Context. Argument and flag errors are marked as usage errors
+// so they map to exitcode.Usage.
+func ExecuteContext(ctx context.Context) error {
+	markUsageErrors(rootCmd)
+
+	err := rootCmd.ExecuteContext(ctx)
+	if err != nil && strings.HasPrefix(err.Error(), "unknown command") {
+		return exitcode.Wrap(exitcode.Usage, err)
+	}
+	return err
+}
+
+func markUsageErrors(c *cobra.Command) {
+	if c.Args != nil {
+		validate := c.Args
+		c.Args = func(cmd *cobra.Command, args []string) error {
+			return exitcode.Wrap(exitcode.Usage, validate(cmd, args))
+		}
+	}
+	for _, child := range c.Commands() {
+		markUsageErrors(child)
+	}
+}
+
+// resolveOutput picks the output format from --json, --plain, --output
+// and --format, falling back to defaults.output_format. Only one of the
+// flags may be given.
+func resolveOutput(cfg *config.Config, outputChanged bool) error {
+	var selected []string
+	if outputJSON {
+		selected = append(selected, "--json")
+	}
+	if outputPlain {
+		selected = append(selected, "--plain")
+	}
+	if outputChanged {
+		selected = append(selected, "--output")
+	}
+	if format != "" {
+		selected = append(selected, "--format")
+	}
+	if len(selected) > 1 {
+		return exitcode.Usagef("%s cannot be combined", strings.Join(selected, " and "))
+	}
+
+	var err error
+	switch {
+	case outputJSON:
+		cfg.Output = config.FormatJSON
+	case outputPlain:
+		cfg.Output = config.FormatPlain
+	case format != "":
+		cfg.Output = config.FormatTemplate
+		cfg.OutputTemplate, err = output.LoadTemplate(format)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+	case outputChanged:
+		cfg.Output, err = config.ParseOutputFormat(outputName)
+		if err != nil {
+			return exitcode.Wrap(exitcode.Usage, err)
+		}
+	default:
+		cfg.Output, err = config.ParseOutputFormat(cfg.Defaults.OutputFormat)
+		if err != nil {
+			return exitcode.Configf("invalid defaults.output_format: %w", err)
+		}
+	}
+	return nil
+}
+
+// JSONRequested reports whether JSON output was selected, so errors can be
+// reported as JSON too, even if the command failed before the
+// configuration was loaded.
+func JSONRequested() bool {
+	if cfg != nil && cfg.Output != "" {
+		return cfg.Output == config.FormatJSON || cfg.Output == config.FormatNDJSON
+	}
+	return outputJSON || outputName == config.FormatJSON || outputName == config.FormatNDJSON
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default: ~/.config/clinkding/config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&url, "url", "u", "", "linkding instance URL")
 	rootCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "API token")
+	rootCmd.PersistentFlags().StringVarP(&profile, "profile", "p", "", "config profile to use (default: current_profile)")
 	rootCmd.PersistentFlags().BoolVar(&outputJSON, "json", false, "output as JSON")
 	rootCmd.PersistentFlags().BoolVar(&outputPlain, "plain", false, "output as plain text")
+	rootCmd.PersistentFlags().StringVar(&outputName, "output", "", "output format: "+strings.Join(config.OutputFormats, ", ")+" (default: defaults.output_format)")
+	rootCmd.PersistentFlags().StringVar(&format, "format", "", "render output with a Go template, or @file to read one")
+	rootCmd.PersistentFlags().StringSliceVar(&columns, "columns", nil, "fields to show in table, plain, CSV and TSV output, e.g. id,title,url")
+	rootCmd.PersistentFlags().StringVar(&sortBy, "sort", "", "sort list output by a field, e.g. added:desc")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "minimal output")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print requests that would change data instead of sending them")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "answer from the local mirror written by \"clinkding sync\" instead of the server")
+	rootCmd.PersistentFlags().IntVar(&retryAttempts, "retry-attempts", 3, "max attempts per request, including the first (1 disables retries)")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxWait, "retry-max-wait", 30*time.Second, "longest wait between retries, including Retry-After")
 
 	rootCmd.Version = version
 
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return exitcode.Wrap(exitcode.Usage, err)
+	})
+
 	// Cobra's built-in completion command
 	rootCmd.CompletionOptions.DisableDefaultCmd = false
 }
@@ -109,10 +259,95 @@ func SetVersionInfo(v, c, d string) {
 	rootCmd.Version = fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date)
 }
 
+// ConfigPath returns the config file that commands editing the
+// configuration should write: the --config flag if given, otherwise the
+// default location.
+func ConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.GetConfigPath()
+}
+
 func GetConfig() *config.Config {
 	return cfg
 }
 
+// NewClient returns an API client for cfg with its retry policy applied.
+func NewClient(cfg *config.Config) *client.Client {
+	policy := client.DefaultRetryPolicy
+	policy.MaxAttempts = cfg.Retry.MaxAttempts
+	policy.MaxWait = cfg.Retry.MaxWait
+	if cfg.Verbose {
+		policy.OnRetry = func(method, path string, attempt int, wait time.Duration, reason error) {
+			fmt.Fprintf(os.Stderr, "Retrying %s %s in %s (attempt %d failed: %v)\n",
+				method, path, wait.Round(time.Millisecond), attempt, reason)
+		}
+	}
+
+	opts := []client.Option{client.WithRetry(policy)}
+	if cfg.DryRun {
+		opts = append(opts, client.WithDryRun(os.Stderr))
+	}
+	if cfg.Offline && offlineMirror != nil {
+		opts = append(opts, client.WithOffline(offlineMirror.Transport(cfg.URL)))
+	}
+	return client.New(cfg.URL, cfg.Token, opts...)
+}
+
+// MirrorPath returns the local mirror file of cfg's profile.
+func MirrorPath(cfg *config.Config) (string, error) {
+	return mirror.Path(cfg.Profile)
+}
+
+// openMirror opens the local mirror for --offline, warning on stderr when
+// it is stale. The mirror stays open until the process exits.
+func openMirror(cfg *config.Config) error {
+	path, err := MirrorPath(cfg)
+	if err != nil {
+		return exitcode.Wrap(exitcode.Config, err)
+	}
+	m, err := mirror.OpenReadOnly(path)
+	if errors.Is(err, mirror.ErrNotSynced) {
+		return exitcode.Configf("no local mirror for --offline yet. Run: clinkding sync")
+	}
+	if err != nil {
+		return exitcode.Wrap(exitcode.Config, err)
+	}
+
+	meta, err := m.Meta()
+	if err != nil {
+		_ = m.Close()
+		return exitcode.Wrap(exitcode.Config, err)
+	}
+	if meta.URL != strings.TrimSuffix(cfg.URL, "/") {
+		_ = m.Close()
+		return exitcode.Configf("the local mirror is of %s, not %s. Run: clinkding sync", meta.URL, cfg.URL)
+	}
+	if age := time.Since(meta.LastSync); age > mirror.StaleAfter && !cfg.Quiet {
+		fmt.Fprintf(os.Stderr, "Warning: the local mirror was last synced %s ago (%s). Run: clinkding sync\n",
+			FormatAge(age), meta.LastSync.Local().Format("2006-01-02 15:04"))
+	}
+
+	offlineMirror = m
+	return nil
+}
+
+// FormatAge describes a duration roughly, in days or hours.
+func FormatAge(d time.Duration) string {
+	if days := int(d.Hours() / 24); days >= 1 {
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	hours := max(int(d.Hours()), 1)
+	if hours == 1 {
+		return "1 hour"
+	}
+	return fmt.Sprintf("%d hours", hours)
+}
+
 func AddCommand(cmd *cobra.Command) {
 	rootCmd.AddCommand(cmd)
 }
@@ -0,0 +1,224 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/mirror"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var syncFull bool
+
+var Cmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update the local mirror used by --offline",
+	Long: `Copy the bookmarks (active and archived), tags and bundles of the
+linkding instance into a local mirror, so that --offline can answer
+"bookmarks list", "bookmarks get", "tags list", "bundles list" and other
+read-only commands without the server.
+
+The first sync copies everything. Later syncs only fetch bookmarks
+modified since the last one. Deleted bookmarks don't show up as changes:
+when the server holds fewer bookmarks than expected, everything is copied
+again, and so it is when the last complete copy is more than a week old,
+which catches deletions that were offset by new bookmarks. Tags and
+bundles are always copied in full. Use --full to force a complete copy.
+
+Each config profile has its own mirror in the user cache directory.
+Commands using --offline warn when the last sync is more than a day old.`,
+	Example: `  clinkding sync
+  clinkding sync --full
+  clinkding bookmarks list --offline --query golang`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{cmd.OnlineAnnotation: ""},
+	RunE:        runSync,
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&syncFull, "full", false, "copy everything instead of only what changed")
+}
+
+type syncSummary struct {
+	Path      string    `json:"path"`
+	Mode      string    `json:"mode"`
+	Bookmarks int       `json:"bookmarks"`
+	Updated   int       `json:"updated"`
+	Deleted   int       `json:"deleted"`
+	Tags      int       `json:"tags"`
+	Bundles   int       `json:"bundles"`
+	SyncedAt  time.Time `json:"synced_at"`
+}
+
+func runSync(cobraCmd *cobra.Command, args []string) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput() && !cfg.Quiet
+
+	path, err := cmd.MirrorPath(cfg)
+	if err != nil {
+		return err
+	}
+	m, err := mirror.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = m.Close() }()
+
+	meta, err := m.Meta()
+	if err != nil {
+		return err
+	}
+	known, err := m.BookmarkIDs()
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(cfg.URL, "/")
+	ctx := cobraCmd.Context()
+	now := time.Now()
+	snapshot := &mirror.Snapshot{Meta: meta}
+	summary := syncSummary{Path: path, Mode: "incremental", SyncedAt: now.UTC()}
+
+	full := syncFull || meta.URL != url || meta.LastFullSync.IsZero()
+	if !full && now.Sub(meta.LastFullSync) > mirror.FullSyncAfter {
+		if human {
+			formatter.Info("The last complete copy is from %s; copying everything", meta.LastFullSync.Local().Format("2006-01-02 15:04"))
+		}
+		full = true
+	}
+	if !full {
+		if human {
+			formatter.Info("Fetching bookmarks modified since %s", meta.Watermark.Local().Format("2006-01-02 15:04"))
+		}
+		changed, err := fetchBookmarks(ctx, bookmarksAPI, meta.Watermark)
+		if err != nil {
+			return err
+		}
+
+		// Deletions don't show up as modifications, but as a count lower
+		// than the bookmarks the mirror would hold, unless new bookmarks
+		// make up for them; FullSyncAfter bounds how long those linger
+		total, err := countBookmarks(ctx, bookmarksAPI)
+		if err != nil {
+			return err
+		}
+		expected := len(known)
+		for _, b := range changed {
+			if !known[b.ID] {
+				expected++
+			}
+		}
+		if expected == total {
+			snapshot.Bookmarks = changed
+			summary.Updated = len(changed)
+			summary.Bookmarks = total
+		} else {
+			if human {
+				formatter.Info("Bookmarks were deleted on the server; copying everything")
+			}
+			full = true
+		}
+	}
+
+	if full {
+		summary.Mode = "full"
+		if human {
+			formatter.Info("Fetching all bookmarks")
+		}
+		all, err := fetchBookmarks(ctx, bookmarksAPI, time.Time{})
+		if err != nil {
+			return err
+		}
+		snapshot.Full = true
+		snapshot.Bookmarks = all
+		snapshot.Meta = mirror.Meta{URL: url, LastFullSync: now}
+		summary.Bookmarks = len(all)
+		summary.Updated = len(all)
+
+		present := make(map[int]bool, len(all))
+		for _, b := range all {
+			present[b.ID] = true
+		}
+		for id := range known {
+			if !present[id] {
+				summary.Deleted++
+			}
+		}
+	}
+
+	if snapshot.Tags, err = api.NewTagsAPI(httpClient).Iterate(1000, 0).All(ctx); err != nil {
+		return err
+	}
+	if snapshot.Bundles, err = api.NewBundlesAPI(httpClient).Iterate().All(ctx); err != nil {
+		return err
+	}
+	summary.Tags = len(snapshot.Tags)
+	summary.Bundles = len(snapshot.Bundles)
+
+	snapshot.Meta.LastSync = now
+	for _, b := range snapshot.Bookmarks {
+		if b.DateModified.After(snapshot.Meta.Watermark) {
+			snapshot.Meta.Watermark = b.DateModified
+		}
+	}
+	if err := m.Save(snapshot); err != nil {
+		return err
+	}
+
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(summary, output.Columns{Plain: []string{"mode", "bookmarks", "updated", "deleted"}})
+	}
+	if summary.Mode == "full" {
+		formatter.Success("Copied %d bookmarks, %d tags and %d bundles to the local mirror", summary.Bookmarks, summary.Tags, summary.Bundles)
+		if summary.Deleted > 0 {
+			formatter.Println("Removed %d bookmarks deleted on the server", summary.Deleted)
+		}
+	} else {
+		formatter.Success("Updated %d bookmarks in the local mirror (%d in total), with %d tags and %d bundles", summary.Updated, summary.Bookmarks, summary.Tags, summary.Bundles)
+	}
+	if cfg.Verbose {
+		formatter.Println("Mirror: %s", path)
+	}
+	return nil
+}
+
+// fetchBookmarks returns the active and archived bookmarks modified since
+// the given time, or all of them if it is zero.
+func fetchBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI, since time.Time) ([]models.Bookmark, error) {
+	var bookmarks []models.Bookmark
+	for _, archived := range []bool{false, true} {
+		opts := &api.ListOptions{Archived: archived, Limit: 100}
+		if !since.IsZero() {
+			opts.ModifiedSince = since.UTC().Format(time.RFC3339)
+		}
+		page, err := bookmarksAPI.Iterate(opts).All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, page...)
+	}
+	return bookmarks, nil
+}
+
+// countBookmarks returns the number of bookmarks on the server, active and
+// archived.
+func countBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI) (int, error) {
+	total := 0
+	for _, archived := range []bool{false, true} {
+		page, err := bookmarksAPI.List(ctx, &api.ListOptions{Archived: archived, Limit: 1})
+		if err != nil {
+			return 0, err
+		}
+		total += page.Count
+	}
+	return total, nil
+}
@@ -1,12 +1,8 @@
 package tags
 
 import (
-	"context"
-	"strconv"
-
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -25,24 +21,24 @@ func runCreate(cobraCmd *cobra.Command, args []string) error {
 	tagName := args[0]
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	tagsAPI := api.NewTagsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	tag, err := tagsAPI.Create(ctx, tagName)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(tag)
+	if cfg.DryRun {
+		formatter.DryRun("would create tag %q", tagName)
+		return nil
 	}
 
-	if cfg.OutputPlain {
-		output.PrintPlainLine(strconv.Itoa(tag.ID), tag.Name)
-		return nil
+	// Output based on format
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(tag, output.Columns{Plain: []string{"id", "name"}})
 	}
 
 	// Human-friendly output
@@ -1,14 +1,12 @@
 package tags
 
 import (
-	"context"
-	"fmt"
 	"strconv"
 	"time"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -25,32 +23,23 @@ var getCmd = &cobra.Command{
 func runGet(cobraCmd *cobra.Command, args []string) error {
 	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("invalid tag ID: %s", args[0])
+		return exitcode.Usagef("invalid tag ID: %s", args[0])
 	}
 
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	tagsAPI := api.NewTagsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	tag, err := tagsAPI.Get(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(tag)
-	}
-
-	if cfg.OutputPlain {
-		output.PrintPlainLine(
-			strconv.Itoa(tag.ID),
-			tag.Name,
-			strconv.Itoa(tag.BookmarkCount),
-		)
-		return nil
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(tag, output.Columns{Plain: []string{"id", "name", "bookmarks"}})
 	}
 
 	// Human-friendly output
@@ -0,0 +1,407 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/fuzzy"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintDistance int
+	lintIgnore   string
+	lintFix      bool
+	lintWorkers  int
+	lintForce    bool
+)
+
+// lintMinLength is the shortest tag name compared by edit distance; short
+// names such as "go" and "js" are too close to everything else.
+const lintMinLength = 4
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Find unused and near-duplicate tags",
+	Long: `Report tags without bookmarks and groups of tags that look like the
+same tag: names differing only in case ("Golang" and "golang"), singular
+and plural forms ("python" and "pythons"), and names within --distance
+edits of each other ("javascript" and "javasript"). Names shorter than 4
+characters are only compared by case and plural.
+
+With --fix, the variants directly related to a group's canonical tag, the
+one on the most bookmarks, are merged into it by rewriting the affected
+bookmarks as "tags merge" does. Variants only related through another
+variant (a chain such as "java", "lava", "lama") are left alone, and so
+are case variants, since linkding already treats them as the same tag.
+Leave tags out of the groups with --ignore. Unused tags are only
+reported, since linkding has no API to delete tags.`,
+	Example: `  clinkding tags lint
+  clinkding tags lint --distance 2 --ignore "java,lava"
+  clinkding tags lint --fix --dry-run
+  clinkding tags lint --json`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().IntVar(&lintDistance, "distance", 1, "max edit distance between similar names (0 disables)")
+	lintCmd.Flags().StringVar(&lintIgnore, "ignore", "", "tags to leave out of the groups (comma-separated)")
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "merge the variants directly related to each group's canonical tag")
+	lintCmd.Flags().IntVar(&lintWorkers, "workers", 4, "number of concurrent requests (with --fix)")
+	lintCmd.Flags().BoolVarP(&lintForce, "force", "f", false, "skip confirmation (with --fix)")
+}
+
+// lintIssue is one finding: an unused tag, or a group of duplicates with
+// the canonical tag first.
+type lintIssue struct {
+	Kind      string   `json:"kind"`
+	Tag       string   `json:"tag"`
+	Bookmarks int      `json:"bookmarks"`
+	Variants  []string `json:"variants,omitempty"`
+	Reasons   []string `json:"reasons,omitempty"`
+}
+
+var lintColumns = output.Columns{
+	Table: []string{"kind", "tag", "bookmarks", "variants", "reasons"},
+	Plain: []string{"kind", "tag", "variants"},
+}
+
+func runLint(cobraCmd *cobra.Command, args []string) error {
+	if lintDistance < 0 {
+		return exitcode.Usagef("--distance can't be negative")
+	}
+	if lintWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	tagsAPI := api.NewTagsAPI(httpClient)
+	formatter := output.New(cfg)
+
+	ctx := cobraCmd.Context()
+	tags, err := tagsAPI.Iterate(1000, 0).All(ctx)
+	if err != nil {
+		return err
+	}
+
+	var ignore []string
+	for _, name := range strings.Split(lintIgnore, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ignore = append(ignore, name)
+		}
+	}
+	issues := lintTags(tags, ignore, lintDistance)
+
+	if lintFix {
+		return fixLint(ctx, formatter, issues)
+	}
+
+	if !cfg.HumanOutput() {
+		enc := formatter.NewEncoder(lintColumns)
+		for _, issue := range issues {
+			if err := enc.Encode(issue); err != nil {
+				return err
+			}
+		}
+		return enc.Close()
+	}
+
+	if len(issues) == 0 {
+		formatter.Success("No problems found in %d tags", len(tags))
+		return nil
+	}
+	printLintReport(formatter, issues)
+	return nil
+}
+
+// lintTags finds unused tags and groups of near-duplicates. Groups are
+// formed transitively: if a matches b and b matches c, all three are one
+// group.
+func lintTags(tags []models.Tag, ignore []string, distance int) []lintIssue {
+	var issues []lintIssue
+	for _, tag := range tags {
+		if tag.BookmarkCount == 0 {
+			issues = append(issues, lintIssue{Kind: "unused", Tag: tag.Name})
+		}
+	}
+
+	var candidates []models.Tag
+	for _, tag := range tags {
+		if !hasTag(ignore, tag.Name) {
+			candidates = append(candidates, tag)
+		}
+	}
+
+	// Union-find over the candidates, remembering why each pair matched
+	parent := make([]int, len(candidates))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	reasons := make(map[int]map[string]bool)
+	for i := range candidates {
+		for j := i + 1; j < len(candidates); j++ {
+			reason := tagRelation(candidates[i].Name, candidates[j].Name, distance)
+			if reason == "" {
+				continue
+			}
+			a, b := find(i), find(j)
+			if a != b {
+				parent[b] = a
+				for r := range reasons[b] {
+					addReason(reasons, a, r)
+				}
+				delete(reasons, b)
+			}
+			addReason(reasons, a, reason)
+		}
+	}
+
+	groups := make(map[int][]models.Tag)
+	var roots []int
+	for i, tag := range candidates {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], tag)
+	}
+
+	for _, root := range roots {
+		group := groups[root]
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return canonicalFirst(group[i], group[j]) })
+
+		issue := lintIssue{Kind: "duplicate", Tag: group[0].Name}
+		for _, tag := range group {
+			issue.Bookmarks += tag.BookmarkCount
+		}
+		for _, tag := range group[1:] {
+			issue.Variants = append(issue.Variants, tag.Name)
+		}
+		for _, reason := range []string{"case", "plural", "similar"} {
+			if reasons[root][reason] {
+				issue.Reasons = append(issue.Reasons, reason)
+			}
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+func addReason(reasons map[int]map[string]bool, group int, reason string) {
+	if reasons[group] == nil {
+		reasons[group] = make(map[string]bool)
+	}
+	reasons[group][reason] = true
+}
+
+// tagRelation reports why two tag names look like the same tag: "case",
+// "plural" or "similar". It returns "" if they don't.
+func tagRelation(a, b string, distance int) string {
+	if strings.EqualFold(a, b) {
+		return "case"
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if isPlural(a, b) || isPlural(b, a) {
+		return "plural"
+	}
+	if distance > 0 && utf8.RuneCountInString(a) >= lintMinLength && utf8.RuneCountInString(b) >= lintMinLength &&
+		fuzzy.Distance(a, b, distance) <= distance {
+		return "similar"
+	}
+	return ""
+}
+
+// isPlural reports whether plural is an English plural of singular.
+func isPlural(singular, plural string) bool {
+	switch {
+	case plural == singular+"s", plural == singular+"es":
+		return true
+	case strings.HasSuffix(singular, "y") && plural == strings.TrimSuffix(singular, "y")+"ies":
+		return true
+	}
+	return false
+}
+
+// canonicalFirst orders a group so the canonical tag comes first: the one
+// on the most bookmarks, then all lowercase, then the shortest name.
+func canonicalFirst(a, b models.Tag) bool {
+	if a.BookmarkCount != b.BookmarkCount {
+		return a.BookmarkCount > b.BookmarkCount
+	}
+	aLower, bLower := a.Name == strings.ToLower(a.Name), b.Name == strings.ToLower(b.Name)
+	if aLower != bLower {
+		return aLower
+	}
+	if len(a.Name) != len(b.Name) {
+		return len(a.Name) < len(b.Name)
+	}
+	return a.Name < b.Name
+}
+
+func printLintReport(formatter *output.Formatter, issues []lintIssue) {
+	var unused, duplicates []lintIssue
+	for _, issue := range issues {
+		if issue.Kind == "unused" {
+			unused = append(unused, issue)
+		} else {
+			duplicates = append(duplicates, issue)
+		}
+	}
+
+	if len(unused) > 0 {
+		formatter.Println("%s", formatter.Bold(fmt.Sprintf("Unused tags (%d)", len(unused))))
+		for _, issue := range unused {
+			formatter.Println("  %s", issue.Tag)
+		}
+		formatter.Println("")
+	}
+	if len(duplicates) > 0 {
+		formatter.Println("%s", formatter.Bold(fmt.Sprintf("Near-duplicate tags (%d groups)", len(duplicates))))
+		for _, issue := range duplicates {
+			formatter.Println("  %s ← %s %s", issue.Tag, strings.Join(issue.Variants, ", "),
+				formatter.Dim("("+strings.Join(issue.Reasons, ", ")+")"))
+		}
+		formatter.Println("")
+		formatter.Info("Run with --fix to merge the variants directly related to each group's first tag")
+	}
+}
+
+// mergeableVariants returns the variants of a duplicate group that --fix
+// merges into its canonical tag: those related to it directly, other than
+// by case. A case variant is the same tag to linkding, so rewriting it
+// changes nothing, and a variant only related through another could be a
+// different tag altogether.
+func mergeableVariants(issue lintIssue, distance int) []string {
+	var variants []string
+	for _, variant := range issue.Variants {
+		switch tagRelation(issue.Tag, variant, distance) {
+		case "plural", "similar":
+			variants = append(variants, variant)
+		}
+	}
+	return variants
+}
+
+// fixLint merges the mergeable variants of every duplicate group into its
+// canonical tag.
+func fixLint(ctx context.Context, formatter *output.Formatter, issues []lintIssue) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	human := cfg.HumanOutput()
+
+	// Find the affected bookmarks up front, so the confirmation is accurate
+	type fixGroup struct {
+		issue     lintIssue
+		bookmarks []models.Bookmark
+	}
+	var groups []fixGroup
+	total := 0
+	for _, issue := range issues {
+		if issue.Kind != "duplicate" {
+			continue
+		}
+		issue.Variants = mergeableVariants(issue, lintDistance)
+		if len(issue.Variants) == 0 {
+			continue
+		}
+		bookmarks, err := findTagged(ctx, bookmarksAPI, issue.Variants)
+		if err != nil {
+			return err
+		}
+		if len(bookmarks) == 0 {
+			continue
+		}
+		groups = append(groups, fixGroup{issue: issue, bookmarks: bookmarks})
+		total += len(bookmarks)
+	}
+
+	if len(groups) == 0 {
+		if human {
+			formatter.Success("Nothing to merge: no bookmarks carry the near-duplicate tags")
+		}
+		return nil
+	}
+
+	if cfg.DryRun {
+		for _, g := range groups {
+			formatter.DryRun("would merge %s into %q on %d bookmarks", quoteTags(g.issue.Variants), g.issue.Tag, len(g.bookmarks))
+		}
+		if !human {
+			enc := formatter.NewEncoder(lintColumns)
+			for _, g := range groups {
+				if err := enc.Encode(g.issue); err != nil {
+					return err
+				}
+			}
+			return enc.Close()
+		}
+		return nil
+	}
+
+	if human {
+		for _, g := range groups {
+			formatter.Println("  %s ← %s (%d bookmarks)", g.issue.Tag, strings.Join(g.issue.Variants, ", "), len(g.bookmarks))
+		}
+		formatter.Println("")
+	}
+	if !lintForce && formatter.IsTTY() {
+		confirmed, err := cmd.Confirm(fmt.Sprintf("Merge %d tag groups on %d bookmarks?", len(groups), total))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+	}
+
+	enc := formatter.NewEncoder(output.Columns{Plain: []string{"to", "updated", "skipped", "failed"}})
+	failed := 0
+	for _, g := range groups {
+		summary, err := retagBookmarks(ctx, bookmarksAPI, formatter, g.bookmarks, g.issue.Variants, g.issue.Tag, lintWorkers)
+		if err != nil {
+			return err
+		}
+		failed += summary.Failed
+		if human {
+			formatter.Println("")
+			printRetagResult(formatter, summary)
+		} else if err := enc.Encode(summary); err != nil {
+			return err
+		}
+	}
+	if !human {
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		if human {
+			formatter.Info("Run the command again to retry the failed bookmarks")
+		}
+		return fmt.Errorf("%d of %d bookmarks failed", failed, total)
+	}
+	return nil
+}
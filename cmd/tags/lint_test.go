@@ -0,0 +1,115 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+func TestTagRelation(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		distance int
+		want     string
+	}{
+		{"Golang", "golang", 1, "case"},
+		{"GOLANG", "golang", 0, "case"},
+		{"python", "pythons", 1, "plural"},
+		{"Pythons", "python", 1, "plural"},
+		{"box", "boxes", 1, "plural"},
+		{"library", "libraries", 0, "plural"},
+		{"libraries", "library", 0, "plural"},
+		{"go", "gos", 1, "plural"},
+		{"javascript", "javasript", 1, "similar"},
+		{"javascript", "javasript", 0, ""},
+		{"kubernetes", "kubernets", 1, "similar"},
+		{"kubernetes", "kuberentes", 1, ""},
+		{"kubernetes", "kuberentes", 2, "similar"},
+		{"java", "lava", 1, "similar"},
+		{"Java", "lava", 1, "similar"},
+		{"java", "jav", 1, ""}, // too short
+		{"go", "js", 2, ""},
+		{"rust", "ruby", 1, ""},
+		{"design", "devops", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := tagRelation(tt.a, tt.b, tt.distance); got != tt.want {
+			t.Errorf("tagRelation(%q, %q, %d) = %q, want %q", tt.a, tt.b, tt.distance, got, tt.want)
+		}
+	}
+}
+
+func TestLintTags(t *testing.T) {
+	tags := []models.Tag{
+		{Name: "lama", BookmarkCount: 1},
+		{Name: "java", BookmarkCount: 9},
+		{Name: "lava", BookmarkCount: 2},
+		{Name: "Golang", BookmarkCount: 3},
+		{Name: "golang", BookmarkCount: 3},
+		{Name: "stale", BookmarkCount: 0},
+		{Name: "recipes", BookmarkCount: 4},
+		{Name: "recipe", BookmarkCount: 5},
+		{Name: "go", BookmarkCount: 7},
+		{Name: "js", BookmarkCount: 1},
+	}
+	tests := []struct {
+		name     string
+		ignore   []string
+		distance int
+		want     []lintIssue
+	}{
+		{
+			name:     "chains group transitively",
+			distance: 1,
+			want: []lintIssue{
+				{Kind: "unused", Tag: "stale"},
+				{Kind: "duplicate", Tag: "java", Bookmarks: 12, Variants: []string{"lava", "lama"}, Reasons: []string{"similar"}},
+				{Kind: "duplicate", Tag: "golang", Bookmarks: 6, Variants: []string{"Golang"}, Reasons: []string{"case"}},
+				{Kind: "duplicate", Tag: "recipe", Bookmarks: 9, Variants: []string{"recipes"}, Reasons: []string{"plural"}},
+			},
+		},
+		{
+			name:     "ignore breaks a chain",
+			ignore:   []string{"LAVA"},
+			distance: 1,
+			want: []lintIssue{
+				{Kind: "unused", Tag: "stale"},
+				{Kind: "duplicate", Tag: "golang", Bookmarks: 6, Variants: []string{"Golang"}, Reasons: []string{"case"}},
+				{Kind: "duplicate", Tag: "recipe", Bookmarks: 9, Variants: []string{"recipes"}, Reasons: []string{"plural"}},
+			},
+		},
+		{
+			name: "no edit distance",
+			want: []lintIssue{
+				{Kind: "unused", Tag: "stale"},
+				{Kind: "duplicate", Tag: "golang", Bookmarks: 6, Variants: []string{"Golang"}, Reasons: []string{"case"}},
+				{Kind: "duplicate", Tag: "recipe", Bookmarks: 9, Variants: []string{"recipes"}, Reasons: []string{"plural"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintTags(tags, tt.ignore, tt.distance)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lintTags:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeableVariants(t *testing.T) {
+	tests := []struct {
+		issue lintIssue
+		want  []string
+	}{
+		{lintIssue{Tag: "java", Variants: []string{"lava", "lama"}}, []string{"lava"}},
+		{lintIssue{Tag: "golang", Variants: []string{"Golang"}}, nil},
+		{lintIssue{Tag: "recipe", Variants: []string{"Recipe", "recipes", "Recipes"}}, []string{"recipes", "Recipes"}},
+	}
+	for _, tt := range tests {
+		if got := mergeableVariants(tt.issue, 1); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mergeableVariants(%s ← %v) = %v, want %v", tt.issue.Tag, tt.issue.Variants, got, tt.want)
+		}
+	}
+}
@@ -2,11 +2,10 @@ package tags
 
 import (
 	"context"
-	"strconv"
 
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/config"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -14,6 +13,7 @@ import (
 var (
 	listLimit  int
 	listOffset int
+	listAll    bool
 )
 
 var listCmd = &cobra.Command{
@@ -21,65 +21,103 @@ var listCmd = &cobra.Command{
 	Short: "List tags",
 	Long:  "List all tags with pagination support.",
 	Example: `  clinkding tags list
-  clinkding tags list --limit 50 --offset 100`,
+  clinkding tags list --limit 50 --offset 100
+  clinkding tags list --all --plain`,
 	RunE: runList,
 }
 
 func init() {
 	listCmd.Flags().IntVar(&listLimit, "limit", 100, "max results")
 	listCmd.Flags().IntVar(&listOffset, "offset", 0, "skip n results")
+	listCmd.Flags().BoolVar(&listAll, "all", false, "fetch every page (--limit sets the page size)")
 }
 
 func runList(cobraCmd *cobra.Command, args []string) error {
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	tagsAPI := api.NewTagsAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
+	if listAll {
+		return listAllTags(ctx, tagsAPI, formatter)
+	}
+
 	result, err := tagsAPI.List(ctx, listLimit, listOffset)
 	if err != nil {
 		return err
 	}
 
-	// Output based on format
-	if cfg.OutputJSON {
+	// JSON keeps the page envelope with the total count and page links
+	if formatter.Format() == config.FormatJSON {
+		if err := formatter.Sort(result.Results); err != nil {
+			return err
+		}
 		return formatter.PrintJSON(result)
 	}
 
-	if cfg.OutputPlain {
-		for _, tag := range result.Results {
-			output.PrintPlainLine(
-				strconv.Itoa(tag.ID),
-				tag.Name,
-				strconv.Itoa(tag.BookmarkCount),
-			)
-		}
+	if len(result.Results) == 0 && cfg.HumanOutput() {
+		formatter.Info("No tags found")
 		return nil
 	}
 
-	// Human-friendly table output
-	if len(result.Results) == 0 {
+	enc := formatter.NewEncoder(tagColumns)
+	for _, tag := range result.Results {
+		if err := enc.Encode(tag); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d tags", result.Count)
+		if result.Next != nil {
+			formatter.Info("Use --offset %d to see more, or --all to fetch everything", listOffset+listLimit)
+		}
+	}
+
+	return nil
+}
+
+// listAllTags follows pagination to the end. Every format except the
+// table is written as each page arrives.
+func listAllTags(ctx context.Context, tagsAPI *api.TagsAPI, formatter *output.Formatter) error {
+	cfg := cmd.GetConfig()
+	it := tagsAPI.Iterate(listLimit, listOffset)
+
+	enc := formatter.NewEncoder(tagColumns)
+	found := 0
+	for it.Next(ctx) {
+		if err := enc.Encode(it.Item()); err != nil {
+			return err
+		}
+		found++
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if found == 0 && cfg.HumanOutput() {
 		formatter.Info("No tags found")
 		return nil
 	}
-
-	table := output.NewTable([]string{"ID", "Name", "Bookmarks", "Created"})
-	for _, tag := range result.Results {
-		table.Append([]string{
-			strconv.Itoa(tag.ID),
-			tag.Name,
-			strconv.Itoa(tag.BookmarkCount),
-			tag.DateAdded.Format("2006-01-02"),
-		})
+	if err := enc.Close(); err != nil {
+		return err
 	}
-	table.Render()
 
-	formatter.Println("")
-	formatter.Println("Total: %d tags", result.Count)
-	if result.Next != nil {
-		formatter.Info("Use --offset %d to see more", listOffset+listLimit)
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		formatter.Println("Total: %d tags", found)
 	}
 
 	return nil
 }
+
+// tagColumns are the fields "tags list" shows by default.
+var tagColumns = output.Columns{
+	Table: []string{"id", "name", "bookmarks", "added"},
+	Plain: []string{"id", "name", "bookmarks"},
+}
@@ -0,0 +1,61 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeInto    string
+	mergeWorkers int
+	mergeForce   bool
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <source>... --into <tag>",
+	Short: "Merge tags into one",
+	Long: `Replace each source tag with the --into tag on every bookmark carrying
+it, active or archived. Bookmarks end up with the --into tag once, however
+many of the sources they had.
+
+If the command is interrupted, run it again: bookmarks already updated no
+longer carry the source tags. The source tags themselves stay in
+linkding, unused.`,
+	Example: `  clinkding tags merge Golang go-lang --into golang
+  clinkding tags merge js ecmascript --into javascript --force`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeInto, "into", "", "tag to merge the sources into (required)")
+	mergeCmd.Flags().IntVar(&mergeWorkers, "workers", 4, "number of concurrent requests")
+	mergeCmd.Flags().BoolVarP(&mergeForce, "force", "f", false, "skip confirmation")
+	_ = mergeCmd.MarkFlagRequired("into")
+}
+
+func runMerge(cobraCmd *cobra.Command, args []string) error {
+	if err := validTagName(mergeInto); err != nil {
+		return err
+	}
+	if mergeWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+
+	// A source that matches the destination is already merged
+	var sources []string
+	for _, source := range args {
+		if !strings.EqualFold(source, mergeInto) && !hasTag(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+	if len(sources) == 0 {
+		return exitcode.Usagef("nothing to merge: every source is %q", mergeInto)
+	}
+
+	question := fmt.Sprintf("Merge %s into %q on %%d bookmarks?", quoteTags(sources), mergeInto)
+	return runRetagCommand(cobraCmd.Context(), sources, mergeInto, mergeWorkers, mergeForce, question)
+}
@@ -0,0 +1,68 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/spf13/cobra"
+)
+
+var (
+	renameWorkers int
+	renameForce   bool
+)
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every bookmark",
+	Long: `Replace a tag with a new name on every bookmark carrying it, active or
+archived.
+
+linkding has no rename endpoint, so each bookmark is updated in turn. If
+the command is interrupted, run it again: bookmarks already updated no
+longer carry the old tag. The old tag itself stays in linkding, unused.`,
+	Example: `  clinkding tags rename golnag golang
+  clinkding tags rename js javascript --force`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	renameCmd.Flags().IntVar(&renameWorkers, "workers", 4, "number of concurrent requests")
+	renameCmd.Flags().BoolVarP(&renameForce, "force", "f", false, "skip confirmation")
+}
+
+func runRename(cobraCmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+	if err := validTagName(newName); err != nil {
+		return err
+	}
+	if strings.EqualFold(oldName, newName) {
+		return exitcode.Usagef("linkding matches tag names case-insensitively, so %q can't be renamed to %q", oldName, newName)
+	}
+	if renameWorkers < 1 {
+		return exitcode.Usagef("--workers must be at least 1")
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	tagsAPI := api.NewTagsAPI(httpClient)
+
+	// Renaming onto an existing tag would merge the two; make that explicit
+	ctx := cobraCmd.Context()
+	tags, err := tagsAPI.Iterate(1000, 0).All(ctx)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, newName) && tag.BookmarkCount != 0 {
+			return exitcode.Usagef("tag %q already exists; use \"clinkding tags merge %s --into %s\" to combine them", tag.Name, oldName, tag.Name)
+		}
+	}
+
+	question := fmt.Sprintf("Rename %q to %q on %%d bookmarks?", oldName, newName)
+	return runRetagCommand(ctx, []string{oldName}, newName, renameWorkers, renameForce, question)
+}
@@ -0,0 +1,250 @@
+package tags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/batch"
+	"github.com/daveonkels/clinkding/internal/config"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+)
+
+// linkding has no endpoint to rename or delete tags, so rename, merge and
+// lint --fix rewrite the tags of every affected bookmark instead. Each run
+// starts by searching for the bookmarks that still carry a source tag, so
+// a run that was interrupted can simply be repeated.
+
+type retagFailure struct {
+	ID    int    `json:"id"`
+	Error string `json:"error"`
+}
+
+type retagSummary struct {
+	From     []string       `json:"from"`
+	To       string         `json:"to"`
+	Total    int            `json:"total"`
+	Updated  int            `json:"updated"`
+	Skipped  int            `json:"skipped"`
+	Failed   int            `json:"failed"`
+	Failures []retagFailure `json:"failures,omitempty"`
+}
+
+// findTagged returns every bookmark, active or archived, carrying one of
+// tags. Tag names are compared case-insensitively, as linkding does.
+func findTagged(ctx context.Context, bookmarksAPI *api.BookmarksAPI, tags []string) ([]models.Bookmark, error) {
+	var found []models.Bookmark
+	seen := make(map[int]bool)
+	for _, tag := range tags {
+		for _, archived := range []bool{false, true} {
+			opts := &api.ListOptions{Query: "#" + tag, Archived: archived, Limit: 100}
+			bookmarks, err := bookmarksAPI.Iterate(opts).All(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			// The search also matches tags in other forms, so check
+			for _, b := range bookmarks {
+				if !seen[b.ID] && hasTag(b.TagNames, tag) {
+					seen[b.ID] = true
+					found = append(found, b)
+				}
+			}
+		}
+	}
+	return found, nil
+}
+
+// replaceTags returns tags with every source tag replaced by dest. dest
+// takes the place of the first tag replaced and appears only once.
+func replaceTags(tags, sources []string, dest string) ([]string, bool) {
+	result := make([]string, 0, len(tags))
+	changed := false
+	for _, tag := range tags {
+		switch {
+		case hasTag(sources, tag):
+			changed = true
+			if !hasTag(result, dest) {
+				result = append(result, dest)
+			}
+		case strings.EqualFold(tag, dest):
+			if !hasTag(result, dest) {
+				result = append(result, tag)
+			}
+		default:
+			result = append(result, tag)
+		}
+	}
+	return result, changed
+}
+
+// retagBookmarks replaces sources with dest on bookmarks, running workers
+// updates at a time. A failed bookmark doesn't stop the others.
+func retagBookmarks(ctx context.Context, bookmarksAPI *api.BookmarksAPI, formatter *output.Formatter, bookmarks []models.Bookmark, sources []string, dest string, workers int) (*retagSummary, error) {
+	cfg := cmd.GetConfig()
+	human := cfg.HumanOutput() && !cfg.Quiet
+
+	change := func(tags []string) ([]string, bool) {
+		return replaceTags(tags, sources, dest)
+	}
+	apply := func(ctx context.Context, b *models.Bookmark) error {
+		_, err := bookmarksAPI.MergeTags(ctx, b.ID, b, time.Time{}, &models.BookmarkUpdate{}, change)
+		return err
+	}
+	progress := func(finished int, r batch.Result[*models.Bookmark]) {
+		if !human {
+			return
+		}
+		switch {
+		case r.Err == nil:
+			formatter.Println("[%d/%d] retagged #%d %s", finished, len(bookmarks), r.Item.ID, r.Item.Title)
+		case errors.Is(r.Err, api.ErrUnchanged):
+			if cfg.Verbose {
+				formatter.Println("[%d/%d] unchanged #%d", finished, len(bookmarks), r.Item.ID)
+			}
+		default:
+			formatter.Warning("[%d/%d] #%d: %v", finished, len(bookmarks), r.Item.ID, r.Err)
+		}
+	}
+
+	items := make([]*models.Bookmark, len(bookmarks))
+	for i := range bookmarks {
+		items[i] = &bookmarks[i]
+	}
+	results := batch.Run(ctx, items, workers, apply, progress)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	summary := &retagSummary{From: sources, To: dest, Total: len(results)}
+	for _, r := range results {
+		switch {
+		case r.Err == nil:
+			summary.Updated++
+		case errors.Is(r.Err, api.ErrUnchanged):
+			summary.Skipped++
+		default:
+			summary.Failed++
+			summary.Failures = append(summary.Failures, retagFailure{ID: r.Item.ID, Error: r.Err.Error()})
+		}
+	}
+	return summary, nil
+}
+
+// runRetagCommand is the shared body of rename and merge: it previews the
+// affected bookmarks, asks for confirmation and retags them.
+func runRetagCommand(ctx context.Context, sources []string, dest string, workers int, force bool, question string) error {
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+	human := cfg.HumanOutput()
+
+	bookmarks, err := findTagged(ctx, bookmarksAPI, sources)
+	if err != nil {
+		return err
+	}
+	if len(bookmarks) == 0 {
+		if !human {
+			return printRetagSummary(formatter, &retagSummary{From: sources, To: dest})
+		}
+		formatter.Info("No bookmarks are tagged %s", quoteTags(sources))
+		return nil
+	}
+
+	if cfg.DryRun {
+		formatter.DryRun("would retag %d bookmarks from %s to %q", len(bookmarks), quoteTags(sources), dest)
+		if !human {
+			return formatter.PrintRecord(retagSummary{From: sources, To: dest, Total: len(bookmarks)}, output.Columns{Plain: []string{"total"}})
+		}
+		return nil
+	}
+
+	if human {
+		formatter.Info("%d bookmarks are tagged %s", len(bookmarks), quoteTags(sources))
+	}
+	if !force && formatter.IsTTY() {
+		confirmed, err := cmd.Confirm(fmt.Sprintf(question, len(bookmarks)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			formatter.Println("Aborted.")
+			return exitcode.ErrAborted
+		}
+	}
+
+	summary, err := retagBookmarks(ctx, bookmarksAPI, formatter, bookmarks, sources, dest, workers)
+	if err != nil {
+		return err
+	}
+	return printRetagSummary(formatter, summary)
+}
+
+func printRetagSummary(formatter *output.Formatter, summary *retagSummary) error {
+	cfg := cmd.GetConfig()
+
+	// Output based on format
+	if formatter.Format() == config.FormatPlain {
+		output.PrintPlainLine(
+			fmt.Sprintf("%d", summary.Updated),
+			fmt.Sprintf("%d", summary.Skipped),
+			fmt.Sprintf("%d", summary.Failed),
+		)
+	} else if !cfg.HumanOutput() {
+		if err := formatter.PrintRecord(summary, output.Columns{}); err != nil {
+			return err
+		}
+	} else {
+		formatter.Println("")
+		printRetagResult(formatter, summary)
+		if summary.Failed > 0 {
+			formatter.Info("Run the command again to retry the failed bookmarks")
+		}
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d bookmarks failed", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+func printRetagResult(formatter *output.Formatter, summary *retagSummary) {
+	formatter.Success("Retagged %s to %q: %d updated, %d unchanged, %d failed (of %d)",
+		quoteTags(summary.From), summary.To, summary.Updated, summary.Skipped, summary.Failed, summary.Total)
+	for _, failure := range summary.Failures {
+		formatter.Println("  #%d: %s", failure.ID, failure.Error)
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteTags(tags []string) string {
+	quoted := make([]string, len(tags))
+	for i, tag := range tags {
+		quoted[i] = fmt.Sprintf("%q", tag)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// validTagName checks a tag name given on the command line. linkding tags
+// can't contain whitespace.
+func validTagName(name string) error {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' }) {
+		return exitcode.Usagef("invalid tag name %q: tags can't be empty or contain whitespace", name)
+	}
+	return nil
+}
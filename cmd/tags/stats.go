@@ -0,0 +1,244 @@
+package tags
+
+import (
+	"context"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/cmd"
+	"github.com/daveonkels/clinkding/internal/api"
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+	"github.com/daveonkels/clinkding/internal/output"
+	"github.com/daveonkels/clinkding/internal/taggraph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsTop       int
+	statsPairs     bool
+	statsGraph     string
+	statsMinWeight int
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how tags are used",
+	Long: `Scan every bookmark, active and archived, and show how often each tag is
+used, when it was first and last used (by bookmark add date), and which
+tags appear together on the same bookmarks.
+
+The --top most used tags are shown, or with --pairs the most frequent
+pairs of tags. --graph writes the whole co-occurrence graph to stdout
+instead, as Graphviz DOT, GraphML or node-link JSON; --min-weight drops
+pairs seen on fewer bookmarks from the pairs and the graph.`,
+	Example: `  clinkding tags stats
+  clinkding tags stats --top 50 --pairs
+  clinkding tags stats --output csv --top 0 > tags.csv
+  clinkding tags stats --graph dot --min-weight 2 | dot -Tsvg > tags.svg
+  clinkding tags stats --graph graphml > tags.graphml`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().IntVar(&statsTop, "top", 20, "number of tags or pairs to show (0 for all)")
+	statsCmd.Flags().BoolVar(&statsPairs, "pairs", false, "show pairs of tags used together instead of tags")
+	statsCmd.Flags().StringVar(&statsGraph, "graph", "", "write the co-occurrence graph ("+strings.Join(taggraph.Formats, ", ")+")")
+	statsCmd.Flags().IntVar(&statsMinWeight, "min-weight", 1, "leave out pairs used together on fewer bookmarks")
+}
+
+// tagStat is the usage of one tag. Unused tags have no dates.
+type tagStat struct {
+	Name      string    `json:"name"`
+	Bookmarks int       `json:"bookmarks"`
+	FirstUsed time.Time `json:"first_used,omitzero"`
+	LastUsed  time.Time `json:"last_used,omitzero"`
+}
+
+// tagPair is two tags and the number of bookmarks carrying both.
+type tagPair struct {
+	Tag       string `json:"tag"`
+	With      string `json:"with"`
+	Bookmarks int    `json:"bookmarks"`
+}
+
+type tagStats struct {
+	Scanned int
+	Tags    []tagStat
+	Pairs   []tagPair
+}
+
+func runStats(cobraCmd *cobra.Command, args []string) error {
+	if statsTop < 0 {
+		return exitcode.Usagef("--top can't be negative")
+	}
+	if statsGraph != "" && !slices.Contains(taggraph.Formats, statsGraph) {
+		return exitcode.Usagef("unsupported graph format %q (supported: %s)", statsGraph, strings.Join(taggraph.Formats, ", "))
+	}
+
+	cfg := cmd.GetConfig()
+	httpClient := cmd.NewClient(cfg)
+	tagsAPI := api.NewTagsAPI(httpClient)
+	bookmarksAPI := api.NewBookmarksAPI(httpClient)
+	formatter := output.New(cfg)
+
+	ctx := cobraCmd.Context()
+	tags, err := tagsAPI.Iterate(1000, 0).All(ctx)
+	if err != nil {
+		return err
+	}
+	stats, err := collectTagStats(ctx, bookmarksAPI, tags)
+	if err != nil {
+		return err
+	}
+
+	if statsGraph != "" {
+		return taggraph.Write(os.Stdout, statsGraph, tagGraph(stats))
+	}
+
+	// Output based on format
+	var records []interface{}
+	if statsPairs {
+		for _, p := range stats.Pairs {
+			records = append(records, p)
+		}
+	} else {
+		for _, t := range stats.Tags {
+			records = append(records, t)
+		}
+	}
+	total := len(records)
+	if statsTop > 0 && total > statsTop {
+		records = records[:statsTop]
+	}
+
+	if len(records) == 0 && cfg.HumanOutput() {
+		if statsPairs {
+			formatter.Info("No tags are used together (of %d bookmarks)", stats.Scanned)
+		} else {
+			formatter.Info("No tags found")
+		}
+		return nil
+	}
+
+	enc := formatter.NewEncoder(output.Columns{})
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	if cfg.HumanOutput() {
+		formatter.Println("")
+		noun := "tags"
+		if statsPairs {
+			noun = "pairs"
+		}
+		formatter.Println("Showing %d of %d %s from %d bookmarks", len(records), total, noun, stats.Scanned)
+	}
+	return nil
+}
+
+// collectTagStats scans every bookmark. Bookmark tag names are matched to
+// the tag list case-insensitively, as linkding does, so case variants are
+// counted together under the spelling listed first.
+func collectTagStats(ctx context.Context, bookmarksAPI *api.BookmarksAPI, tags []models.Tag) (*tagStats, error) {
+	byName := make(map[string]*tagStat)
+	for _, tag := range tags {
+		if _, ok := byName[strings.ToLower(tag.Name)]; !ok {
+			byName[strings.ToLower(tag.Name)] = &tagStat{Name: tag.Name}
+		}
+	}
+	pairs := make(map[[2]string]int)
+
+	scanned := 0
+	for _, archived := range []bool{false, true} {
+		it := bookmarksAPI.Iterate(&api.ListOptions{Archived: archived, Limit: 100})
+		for it.Next(ctx) {
+			b := it.Item()
+			scanned++
+
+			var names []string
+			for _, name := range b.TagNames {
+				key := strings.ToLower(name)
+				stat, ok := byName[key]
+				if !ok {
+					stat = &tagStat{Name: name}
+					byName[key] = stat
+				}
+				if slices.Contains(names, stat.Name) {
+					continue
+				}
+				names = append(names, stat.Name)
+
+				stat.Bookmarks++
+				if stat.FirstUsed.IsZero() || b.DateAdded.Before(stat.FirstUsed) {
+					stat.FirstUsed = b.DateAdded
+				}
+				if b.DateAdded.After(stat.LastUsed) {
+					stat.LastUsed = b.DateAdded
+				}
+			}
+
+			sort.Strings(names)
+			for i := range names {
+				for j := i + 1; j < len(names); j++ {
+					pairs[[2]string{names[i], names[j]}]++
+				}
+			}
+		}
+		if err := it.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	stats := &tagStats{Scanned: scanned}
+	for _, stat := range byName {
+		stats.Tags = append(stats.Tags, *stat)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		a, b := stats.Tags[i], stats.Tags[j]
+		if a.Bookmarks != b.Bookmarks {
+			return a.Bookmarks > b.Bookmarks
+		}
+		return a.Name < b.Name
+	})
+
+	for key, count := range pairs {
+		if count >= statsMinWeight {
+			stats.Pairs = append(stats.Pairs, tagPair{Tag: key[0], With: key[1], Bookmarks: count})
+		}
+	}
+	sort.Slice(stats.Pairs, func(i, j int) bool {
+		a, b := stats.Pairs[i], stats.Pairs[j]
+		if a.Bookmarks != b.Bookmarks {
+			return a.Bookmarks > b.Bookmarks
+		}
+		if a.Tag != b.Tag {
+			return a.Tag < b.Tag
+		}
+		return a.With < b.With
+	})
+	return stats, nil
+}
+
+// tagGraph builds the co-occurrence graph of the used tags.
+func tagGraph(stats *tagStats) *taggraph.Graph {
+	g := &taggraph.Graph{Nodes: []taggraph.Node{}, Edges: []taggraph.Edge{}}
+	for _, t := range stats.Tags {
+		if t.Bookmarks > 0 {
+			g.Nodes = append(g.Nodes, taggraph.Node{Name: t.Name, Bookmarks: t.Bookmarks})
+		}
+	}
+	for _, p := range stats.Pairs {
+		g.Edges = append(g.Edges, taggraph.Edge{Source: p.Tag, Target: p.With, Weight: p.Bookmarks})
+	}
+	return g
+}
@@ -7,11 +7,15 @@ import (
 var Cmd = &cobra.Command{
 	Use:   "tags",
 	Short: "Manage tags",
-	Long:  "Commands for listing, getting, and creating tags.",
+	Long:  "Commands for listing, getting, creating, renaming, merging, linting and analyzing tags.",
 }
 
 func init() {
 	Cmd.AddCommand(listCmd)
 	Cmd.AddCommand(getCmd)
 	Cmd.AddCommand(createCmd)
+	Cmd.AddCommand(renameCmd)
+	Cmd.AddCommand(mergeCmd)
+	Cmd.AddCommand(lintCmd)
+	Cmd.AddCommand(statsCmd)
 }
@@ -1,11 +1,8 @@
 package user
 
 import (
-	"context"
-
 	"github.com/daveonkels/clinkding/cmd"
 	"github.com/daveonkels/clinkding/internal/api"
-	"github.com/daveonkels/clinkding/internal/client"
 	"github.com/daveonkels/clinkding/internal/output"
 	"github.com/spf13/cobra"
 )
@@ -21,29 +18,19 @@ var profileCmd = &cobra.Command{
 
 func runProfile(cobraCmd *cobra.Command, args []string) error {
 	cfg := cmd.GetConfig()
-	httpClient := client.New(cfg.URL, cfg.Token)
+	httpClient := cmd.NewClient(cfg)
 	userAPI := api.NewUserAPI(httpClient)
 	formatter := output.New(cfg)
 
-	ctx := context.Background()
+	ctx := cobraCmd.Context()
 	profile, err := userAPI.GetProfile(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Output based on format
-	if cfg.OutputJSON {
-		return formatter.PrintJSON(profile)
-	}
-
-	if cfg.OutputPlain {
-		output.PrintPlainLine(
-			profile.Theme,
-			profile.BookmarkDateDisplay,
-			boolToString(profile.EnableSharing),
-			boolToString(profile.EnableFavicons),
-		)
-		return nil
+	if !cfg.HumanOutput() {
+		return formatter.PrintRecord(profile, output.Columns{Plain: []string{"theme", "bookmark_date_display", "enable_sharing", "enable_favicons"}})
 	}
 
 	// Human-friendly output
@@ -73,10 +60,3 @@ func runProfile(cobraCmd *cobra.Command, args []string) error {
 
 	return nil
 }
-
-func boolToString(b bool) string {
-	if b {
-		return "true"
-	}
-	return "false"
-}
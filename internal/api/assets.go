@@ -25,6 +25,13 @@ func (a *AssetsAPI) List(ctx context.Context, bookmarkID int) (*models.AssetList
 	return &result, nil
 }
 
+// Iterate returns an iterator over every asset of a bookmark, following
+// pagination.
+func (a *AssetsAPI) Iterate(bookmarkID int) *Iterator[models.Asset] {
+	path := fmt.Sprintf("/api/bookmarks/%d/assets/", bookmarkID)
+	return newIterator[models.Asset](a.client, path)
+}
+
 func (a *AssetsAPI) Get(ctx context.Context, bookmarkID, assetID int) (*models.Asset, error) {
 	path := fmt.Sprintf("/api/bookmarks/%d/assets/%d/", bookmarkID, assetID)
 	var asset models.Asset
@@ -35,17 +42,34 @@ func (a *AssetsAPI) Get(ctx context.Context, bookmarkID, assetID int) (*models.A
 }
 
 func (a *AssetsAPI) Upload(ctx context.Context, bookmarkID int, filePath string) (*models.Asset, error) {
+	upload, err := client.FileUpload(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return a.UploadFrom(ctx, bookmarkID, upload)
+}
+
+// UploadFrom uploads an asset from any source, such as stdin, under the
+// upload's name.
+func (a *AssetsAPI) UploadFrom(ctx context.Context, bookmarkID int, upload *client.Upload) (*models.Asset, error) {
 	path := fmt.Sprintf("/api/bookmarks/%d/assets/upload/", bookmarkID)
 	var result models.Asset
-	if err := a.client.UploadFile(ctx, path, filePath, &result); err != nil {
+	if err := a.client.UploadFile(ctx, path, upload, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (a *AssetsAPI) Download(ctx context.Context, bookmarkID, assetID int, outputPath string) error {
+	_, err := a.DownloadWith(ctx, bookmarkID, assetID, outputPath, nil)
+	return err
+}
+
+// DownloadWith downloads an asset with progress reporting or checksum
+// verification. It returns the file's SHA-256 when opts asks for one.
+func (a *AssetsAPI) DownloadWith(ctx context.Context, bookmarkID, assetID int, outputPath string, opts *client.Download) (string, error) {
 	path := fmt.Sprintf("/api/bookmarks/%d/assets/%d/download/", bookmarkID, assetID)
-	return a.client.DownloadFile(ctx, path, outputPath)
+	return a.client.DownloadFile(ctx, path, outputPath, opts)
 }
 
 func (a *AssetsAPI) Delete(ctx context.Context, bookmarkID, assetID int) error {
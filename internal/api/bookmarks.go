@@ -2,11 +2,14 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 	"github.com/daveonkels/clinkding/internal/models"
 )
 
@@ -19,6 +22,21 @@ func NewBookmarksAPI(client *client.Client) *BookmarksAPI {
 }
 
 func (a *BookmarksAPI) List(ctx context.Context, opts *ListOptions) (*models.BookmarkList, error) {
+	var result models.BookmarkList
+	if err := a.client.Get(ctx, a.listPath(opts), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Iterate returns an iterator over every bookmark matching opts, following
+// pagination until the last page. opts.Limit sets the page size and
+// opts.Offset the starting position.
+func (a *BookmarksAPI) Iterate(opts *ListOptions) *Iterator[models.Bookmark] {
+	return newIterator[models.Bookmark](a.client, a.listPath(opts))
+}
+
+func (a *BookmarksAPI) listPath(opts *ListOptions) string {
 	params := url.Values{}
 
 	if opts != nil {
@@ -47,13 +65,7 @@ func (a *BookmarksAPI) List(ctx context.Context, opts *ListOptions) (*models.Boo
 		path = "/api/bookmarks/archived/"
 	}
 
-	path = a.client.BuildURL(path, params)
-
-	var result models.BookmarkList
-	if err := a.client.Get(ctx, path, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return a.client.BuildURL(path, params)
 }
 
 func (a *BookmarksAPI) Get(ctx context.Context, id int) (*models.Bookmark, error) {
@@ -94,6 +106,79 @@ func (a *BookmarksAPI) Update(ctx context.Context, id int, bookmark *models.Book
 	return &result, nil
 }
 
+// UpdateIfUnmodified applies bookmark only if the bookmark hasn't been
+// modified after since, which is usually the DateModified of the copy the
+// change was based on. linkding has no conditional requests, so the check
+// is a fresh read just before the write; it narrows the window for lost
+// updates rather than closing it.
+func (a *BookmarksAPI) UpdateIfUnmodified(ctx context.Context, id int, since time.Time, bookmark *models.BookmarkUpdate) (*models.Bookmark, error) {
+	current, err := a.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.DateModified.After(since) {
+		return nil, &ModifiedError{ID: id, Modified: current.DateModified}
+	}
+	return a.Update(ctx, id, bookmark)
+}
+
+// mergeAttempts is how often MergeTags reads and merges again when the
+// bookmark keeps changing under it.
+const mergeAttempts = 3
+
+// ErrUnchanged is returned by MergeTags when the change leaves the tags as
+// they are, so nothing was sent.
+var ErrUnchanged = errors.New("unchanged")
+
+// MergeTags sends update with its tags set to change(current tags).
+// current is the copy the caller already read, or nil to fetch one. If the
+// bookmark is modified between the read and the write, it is read again
+// and the change merged anew, so concurrent edits aren't lost. A non-zero
+// since refuses the update, without retrying, once the bookmark was
+// modified after it. change returns false when there is nothing to do.
+func (a *BookmarksAPI) MergeTags(ctx context.Context, id int, current *models.Bookmark, since time.Time, update *models.BookmarkUpdate, change func(tags []string) ([]string, bool)) (*models.Bookmark, error) {
+	for attempt := 1; ; attempt++ {
+		if current == nil {
+			var err error
+			if current, err = a.Get(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		if !since.IsZero() && current.DateModified.After(since) {
+			return nil, &ModifiedError{ID: id, Modified: current.DateModified}
+		}
+
+		tags, changed := change(current.TagNames)
+		if !changed {
+			return current, ErrUnchanged
+		}
+		update.TagNames = tags
+
+		result, err := a.UpdateIfUnmodified(ctx, id, current.DateModified, update)
+		var modified *ModifiedError
+		if errors.As(err, &modified) && since.IsZero() && attempt < mergeAttempts {
+			current = nil
+			continue
+		}
+		return result, err
+	}
+}
+
+// ModifiedError reports that a bookmark changed after the time an update
+// was based on.
+type ModifiedError struct {
+	ID       int
+	Modified time.Time
+}
+
+func (e *ModifiedError) Error() string {
+	return fmt.Sprintf("bookmark #%d was modified at %s", e.ID, e.Modified.Local().Format(time.RFC3339))
+}
+
+func (e *ModifiedError) GetExitCode() int {
+	return exitcode.Validation
+}
+
 func (a *BookmarksAPI) Archive(ctx context.Context, id int) error {
 	path := fmt.Sprintf("/api/bookmarks/%d/archive/", id)
 	return a.client.Post(ctx, path, nil, nil)
@@ -24,6 +24,11 @@ func (a *BundlesAPI) List(ctx context.Context) (*models.BundleList, error) {
 	return &result, nil
 }
 
+// Iterate returns an iterator over every bundle, following pagination.
+func (a *BundlesAPI) Iterate() *Iterator[models.Bundle] {
+	return newIterator[models.Bundle](a.client, "/api/bundles/")
+}
+
 func (a *BundlesAPI) Get(ctx context.Context, id int) (*models.Bundle, error) {
 	path := fmt.Sprintf("/api/bundles/%d/", id)
 	var bundle models.Bundle
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+
+	"github.com/daveonkels/clinkding/internal/client"
+)
+
+// page is the common envelope of every paginated linkding list endpoint.
+type page[T any] struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []T     `json:"results"`
+}
+
+// Iterator walks a paginated list endpoint item by item, following the
+// "next" URL of each page until the server stops returning one. Only the
+// current page is held in memory.
+//
+//	it := bookmarksAPI.Iterate(opts)
+//	for it.Next(ctx) {
+//		bookmark := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	client  *client.Client
+	next    string
+	items   []T
+	current T
+	count   int
+	started bool
+	err     error
+}
+
+func newIterator[T any](c *client.Client, path string) *Iterator[T] {
+	return &Iterator[T]{client: c, next: path}
+}
+
+// Next advances to the next item, fetching the following page when the
+// current one is exhausted. It returns false when there are no more items,
+// the context is cancelled, or a request fails; check Err afterwards.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	for len(it.items) == 0 {
+		if it.next == "" {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if err := it.fetch(ctx); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+func (it *Iterator[T]) fetch(ctx context.Context) error {
+	var result page[T]
+	if err := it.client.Get(ctx, it.next, &result); err != nil {
+		return err
+	}
+
+	if !it.started {
+		it.count = result.Count
+		it.started = true
+	}
+	it.items = result.Results
+	it.next = ""
+
+	if result.Next != nil && *result.Next != "" {
+		next, err := it.client.RelativePath(*result.Next)
+		if err != nil {
+			return err
+		}
+		it.next = next
+	}
+
+	return nil
+}
+
+// Item returns the item the last call to Next advanced to.
+func (it *Iterator[T]) Item() T {
+	return it.current
+}
+
+// Count returns the total number of items reported by the server. It is
+// only known once the first page has been fetched.
+func (it *Iterator[T]) Count() int {
+	return it.count
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// All drains the iterator into a slice. Prefer Next for large result sets.
+func (it *Iterator[T]) All(ctx context.Context) ([]T, error) {
+	var items []T
+	for it.Next(ctx) {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
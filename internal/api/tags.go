@@ -19,6 +19,20 @@ func NewTagsAPI(client *client.Client) *TagsAPI {
 }
 
 func (a *TagsAPI) List(ctx context.Context, limit, offset int) (*models.TagList, error) {
+	var result models.TagList
+	if err := a.client.Get(ctx, a.listPath(limit, offset), &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Iterate returns an iterator over every tag, fetching limit tags per page
+// starting at offset.
+func (a *TagsAPI) Iterate(limit, offset int) *Iterator[models.Tag] {
+	return newIterator[models.Tag](a.client, a.listPath(limit, offset))
+}
+
+func (a *TagsAPI) listPath(limit, offset int) string {
 	params := url.Values{}
 	if limit > 0 {
 		params.Add("limit", strconv.Itoa(limit))
@@ -27,13 +41,7 @@ func (a *TagsAPI) List(ctx context.Context, limit, offset int) (*models.TagList,
 		params.Add("offset", strconv.Itoa(offset))
 	}
 
-	path := a.client.BuildURL("/api/tags/", params)
-
-	var result models.TagList
-	if err := a.client.Get(ctx, path, &result); err != nil {
-		return nil, err
-	}
-	return &result, nil
+	return a.client.BuildURL("/api/tags/", params)
 }
 
 func (a *TagsAPI) Get(ctx context.Context, id int) (*models.Tag, error) {
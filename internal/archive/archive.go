@@ -0,0 +1,219 @@
+// Package archive implements the on-disk format of clinkding backups: a
+// directory (optionally packed as .tar.gz) holding a manifest and one JSON
+// file per kind of data.
+//
+//	manifest.json
+//	bookmarks.json   active and archived bookmarks
+//	tags.json
+//	bundles.json
+//	profile.json
+//	assets.json      asset metadata, with the path of each file
+//	assets/<bookmark-id>/<asset-id>/<display-name>
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+const (
+	FormatName    = "clinkding-backup"
+	FormatVersion = 1
+
+	ManifestFile  = "manifest.json"
+	BookmarksFile = "bookmarks.json"
+	TagsFile      = "tags.json"
+	BundlesFile   = "bundles.json"
+	ProfileFile   = "profile.json"
+	AssetsFile    = "assets.json"
+)
+
+// Manifest describes a backup so it can be validated before restoring.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	SourceURL string    `json:"source_url"`
+	Assets    bool      `json:"assets"`
+	Counts    Counts    `json:"counts"`
+}
+
+type Counts struct {
+	Bookmarks int `json:"bookmarks"`
+	Archived  int `json:"archived"`
+	Tags      int `json:"tags"`
+	Bundles   int `json:"bundles"`
+	Assets    int `json:"assets"`
+}
+
+// AssetEntry is an asset's metadata plus the location of its file inside
+// the backup.
+type AssetEntry struct {
+	models.Asset
+	Path string `json:"path"`
+}
+
+// Dir is a backup laid out as a directory, either being written or opened
+// for reading.
+type Dir struct {
+	Path     string
+	Manifest Manifest
+
+	// cleanup removes the temporary directory a packed backup was
+	// extracted to.
+	cleanup func() error
+}
+
+// Create prepares an empty directory for a new backup.
+func Create(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	return &Dir{Path: path}, nil
+}
+
+// Open opens an existing backup directory or .tar.gz archive and reads its
+// manifest.
+func Open(path string) (*Dir, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	d := &Dir{Path: path}
+	if !info.IsDir() {
+		tmp, err := os.MkdirTemp("", "clinkding-restore-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		d.Path = tmp
+		d.cleanup = func() error { return os.RemoveAll(tmp) }
+
+		if err := unpack(path, tmp); err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+	}
+
+	if err := d.ReadJSON(ManifestFile, &d.Manifest); err != nil {
+		_ = d.Close()
+		return nil, fmt.Errorf("not a clinkding backup: %w", err)
+	}
+	if d.Manifest.Format != FormatName {
+		_ = d.Close()
+		return nil, fmt.Errorf("not a clinkding backup: unexpected format %q", d.Manifest.Format)
+	}
+	if d.Manifest.Version > FormatVersion {
+		_ = d.Close()
+		return nil, fmt.Errorf("backup format version %d is newer than supported (%d); upgrade clinkding",
+			d.Manifest.Version, FormatVersion)
+	}
+
+	return d, nil
+}
+
+// Close releases temporary files created by Open.
+func (d *Dir) Close() error {
+	if d.cleanup == nil {
+		return nil
+	}
+	return d.cleanup()
+}
+
+// File returns the local path of a file inside the backup. name uses
+// forward slashes regardless of platform.
+func (d *Dir) File(name string) string {
+	return filepath.Join(d.Path, filepath.FromSlash(name))
+}
+
+// AssetFile returns the local path of an asset's file from its path in
+// assets.json, refusing paths outside the assets directory.
+func (d *Dir) AssetFile(name string) (string, error) {
+	clean, err := cleanPath(name)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(clean, "assets/") {
+		return "", fmt.Errorf("invalid asset path in backup: %q", name)
+	}
+	return d.File(clean), nil
+}
+
+// Create creates a file inside the backup, including parent directories.
+func (d *Dir) Create(name string) (*os.File, error) {
+	path := d.File(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", name, err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	return file, nil
+}
+
+func (d *Dir) WriteJSON(name string, v interface{}) error {
+	file, err := d.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return file.Close()
+}
+
+func (d *Dir) ReadJSON(name string, v interface{}) error {
+	file, err := os.Open(d.File(name))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := json.NewDecoder(file).Decode(v); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// WriteManifest stamps the manifest with the format identifiers and writes
+// it. It should be written last, so an interrupted backup has none.
+func (d *Dir) WriteManifest(m Manifest) error {
+	m.Format = FormatName
+	m.Version = FormatVersion
+	d.Manifest = m
+	return d.WriteJSON(ManifestFile, m)
+}
+
+// AssetPath returns where an asset's file is stored inside a backup.
+func AssetPath(asset models.Asset) string {
+	name := filepath.Base(filepath.Clean(asset.DisplayName))
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == 0 {
+			return '_'
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == ".." {
+		name = "asset-" + strconv.Itoa(asset.ID)
+	}
+	return fmt.Sprintf("assets/%d/%d/%s", asset.BookmarkID, asset.ID, name)
+}
+
+// IsPacked reports whether path names a .tar.gz archive rather than a
+// directory.
+func IsPacked(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
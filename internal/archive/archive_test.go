@@ -0,0 +1,77 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssetFile(t *testing.T) {
+	d := &Dir{Path: "/backup"}
+	tests := []struct {
+		path string
+		want string // "" means refused
+	}{
+		{"assets/1/2/page.html", "/backup/assets/1/2/page.html"},
+		{"assets/1/./2/page.html", "/backup/assets/1/2/page.html"},
+		{"assets/1/2/../3/page.html", ""},
+		{"../../../home/u/.ssh/id_rsa", ""},
+		{"assets/../../secret", ""},
+		{`assets/1/2/..\..\secret`, ""},
+		{"/etc/passwd", ""},
+		{"bookmarks.json", ""},
+		{"assets", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := d.AssetFile(tt.path)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("AssetFile(%q) = %q, want an error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil || got != filepath.FromSlash(tt.want) {
+			t.Errorf("AssetFile(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestUnpackRefusesEscapes(t *testing.T) {
+	for _, name := range []string{"../escaped", "assets/../../escaped", "/tmp/escaped"} {
+		src := filepath.Join(t.TempDir(), "backup.tar.gz")
+		writeTar(t, src, name)
+		dir := t.TempDir()
+		if err := unpack(src, filepath.Join(dir, "out")); err == nil {
+			t.Errorf("unpack accepted %q", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "escaped")); err == nil {
+			t.Errorf("unpack of %q wrote outside the directory", name)
+		}
+	}
+}
+
+// writeTar writes a tarball at path holding one small file called name.
+func writeTar(t *testing.T, path, name string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	content := []byte("secret")
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []interface{ Close() error }{tw, gz, file} {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
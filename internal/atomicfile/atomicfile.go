@@ -0,0 +1,72 @@
+// Package atomicfile writes files so they appear complete or not at all:
+// the contents go to a temporary file in the same directory, which
+// replaces the destination only once everything was written.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File is a file being written in place of path.
+type File struct {
+	*os.File
+	path string
+	perm os.FileMode
+	done bool
+}
+
+// Create starts writing a file that will replace path with permissions
+// perm when committed.
+func Create(path string, perm os.FileMode) (*File, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return &File{File: tmp, path: path, perm: perm}, nil
+}
+
+// Commit closes the file and moves it into place.
+func (f *File) Commit() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	err := f.File.Close()
+	if err == nil {
+		err = os.Chmod(f.Name(), f.perm)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// Abort closes and removes the file, leaving path untouched. It does
+// nothing after Commit, so it can be deferred.
+func (f *File) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+	_ = f.File.Close()
+	_ = os.Remove(f.Name())
+}
+
+// WriteFile writes data to path like os.WriteFile, but atomically.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := Create(path, perm)
+	if err != nil {
+		return err
+	}
+	defer f.Abort()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Commit()
+}
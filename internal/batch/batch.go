@@ -0,0 +1,58 @@
+// Package batch runs an operation over many items with a bounded number of
+// concurrent workers.
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// Result is the outcome of one item.
+type Result[T any] struct {
+	Item T
+	Err  error
+}
+
+// Run calls fn for every item on at most workers goroutines and returns the
+// results in item order. A failing item doesn't stop the others. done, if
+// not nil, is called as each item finishes with the number finished so
+// far; calls never overlap. Once ctx is canceled, items that haven't
+// started fail with ctx.Err().
+func Run[T any](ctx context.Context, items []T, workers int, fn func(context.Context, T) error, done func(finished int, r Result[T])) []Result[T] {
+	workers = max(1, min(workers, len(items)))
+	results := make([]Result[T], len(items))
+	indexes := make(chan int)
+
+	var mu sync.Mutex
+	finished := 0
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				r := Result[T]{Item: items[i]}
+				if r.Err = ctx.Err(); r.Err == nil {
+					r.Err = fn(ctx, items[i])
+				}
+				results[i] = r
+
+				mu.Lock()
+				finished++
+				if done != nil {
+					done(finished, r)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
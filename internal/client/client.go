@@ -3,60 +3,121 @@ package client
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
 )
 
 const (
-	defaultTimeout = 30 * time.Second
+	defaultTimeout  = 30 * time.Second
+	jsonContentType = "application/json"
 )
 
 type Client struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	retry      RetryPolicy
+	dryRun     io.Writer
+	offline    http.RoundTripper
+}
+
+// Option configures optional Client behavior.
+type Option func(*Client)
+
+// WithRetry replaces the default retry policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithDryRun makes the client describe every request except GETs on w
+// instead of sending it, so nothing on the server changes.
+func WithDryRun(w io.Writer) Option {
+	return func(c *Client) {
+		c.dryRun = w
+	}
 }
 
-func New(baseURL, token string) *Client {
-	return &Client{
+// WithOffline answers every request from source instead of the server,
+// such as a local mirror. Requests aren't retried, and errors from source
+// are returned as they are.
+func WithOffline(source http.RoundTripper) Option {
+	return func(c *Client) {
+		c.offline = source
+	}
+}
+
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		token:   token,
 		httpClient: &http.Client{
 			Timeout: defaultTimeout,
 		},
+		retry: DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
-func (c *Client) do(ctx context.Context, method, path string, body io.Reader, result interface{}) error {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
+// request describes a single API call. body is a factory so the payload can
+// be rebuilt for every attempt; it may be nil for requests without a body.
+type request struct {
+	method      string
+	path        string
+	body        func() (io.Reader, error)
+	contentType string
 
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	// length is the body's length when the reader doesn't tell, so it is
+	// sent as a Content-Length; 0 leaves it to the reader
+	length int64
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.token))
-	if body != nil && method != http.MethodGet {
-		req.Header.Set("Content-Type", "application/json")
-	}
+	// streaming requests may run longer than the client timeout
+	streaming bool
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	// header holds extra request headers
+	header http.Header
+
+	// summary describes a body that isn't JSON in dry-run output
+	summary string
+}
+
+// DryRun reports whether the client only describes changing requests.
+func (c *Client) DryRun() bool {
+	return c.dryRun != nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, result interface{}) error {
+	req := request{method: method, path: path}
+	if body != nil {
+		req.body = func() (io.Reader, error) { return bytes.NewReader(body), nil }
+		req.contentType = jsonContentType
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if err := checkResponse(resp); err != nil {
+	resp, err := c.send(ctx, req)
+	if err != nil {
 		return err
 	}
+	defer func() { _ = resp.Body.Close() }()
 
 	if result != nil && resp.StatusCode != http.StatusNoContent {
 		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
@@ -67,20 +128,198 @@ func (c *Client) do(ctx context.Context, method, path string, body io.Reader, re
 	return nil
 }
 
+// send performs r, retrying according to the client's retry policy, and
+// returns the first successful response. The caller must close its body.
+func (c *Client) send(ctx context.Context, r request) (*http.Response, error) {
+	if c.dryRun != nil && r.method != http.MethodGet {
+		return c.simulate(r)
+	}
+	if c.offline != nil {
+		return c.answerOffline(ctx, r)
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		// Track whether any part of the request reached the wire, so
+		// non-idempotent requests are only retried when it provably did not.
+		var wroteHeaders atomic.Bool
+		trace := &httptrace.ClientTrace{
+			WroteHeaders: func() { wroteHeaders.Store(true) },
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+		httpClient := c.httpClient
+		if r.streaming {
+			unbounded := *c.httpClient
+			unbounded.Timeout = 0
+			httpClient = &unbounded
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			retryable := ctx.Err() == nil && (isIdempotent(r.method) || !wroteHeaders.Load())
+			if retryable && attempt < c.retry.MaxAttempts {
+				wait := c.retry.backoff(attempt)
+				c.retry.notify(r.method, r.path, attempt, wait, err)
+				if err := sleep(ctx, wait); err != nil {
+					return nil, fmt.Errorf("request failed: %w", err)
+				}
+				continue
+			}
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		if isRetryableStatus(resp.StatusCode) && isIdempotent(r.method) && attempt < c.retry.MaxAttempts {
+			if wait, ok := c.retry.delay(attempt, resp); ok {
+				reason := fmt.Errorf("server responded %s", resp.Status)
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+
+				c.retry.notify(r.method, r.path, attempt, wait, reason)
+				if err := sleep(ctx, wait); err != nil {
+					return nil, fmt.Errorf("request failed: %w", err)
+				}
+				continue
+			}
+		}
+
+		if err := checkResponse(resp); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+
+		return resp, nil
+	}
+}
+
+// answerOffline answers r from the client's offline source.
+func (c *Client) answerOffline(ctx context.Context, r request) (*http.Response, error) {
+	req, err := c.newRequest(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.offline.RoundTrip(req)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// simulate prints r in dry-run mode and returns a synthetic response in its
+// place. JSON requests get their own body back, so callers decoding a
+// result see the values they sent; the ID and server-set fields stay
+// empty. Other bodies are only described by their summary and never read.
+func (c *Client) simulate(r request) (*http.Response, error) {
+	var data []byte
+	if r.body != nil && r.contentType == jsonContentType {
+		body, err := r.body()
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "DRY RUN: %s %s%s\n", r.method, c.baseURL, r.path)
+	fmt.Fprintf(&out, "Authorization: Token <redacted>\n")
+	switch {
+	case r.contentType == jsonContentType:
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, data, "", "  "); err != nil {
+			indented.Write(data)
+		}
+		fmt.Fprintf(&out, "Content-Type: %s\n%s\n", r.contentType, indented.String())
+	case r.summary != "":
+		mediaType, _, _ := strings.Cut(r.contentType, ";")
+		fmt.Fprintf(&out, "Content-Type: %s\n%s\n", mediaType, r.summary)
+	}
+
+	// The token never appears in a body we build, but make sure of it
+	text := out.String()
+	if c.token != "" {
+		text = strings.ReplaceAll(text, c.token, "<redacted>")
+	}
+	if _, err := io.WriteString(c.dryRun, text); err != nil {
+		return nil, err
+	}
+
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}
+	switch {
+	case r.method == http.MethodDelete:
+		resp.StatusCode, resp.Status = http.StatusNoContent, "204 No Content"
+		resp.Body = http.NoBody
+	case r.contentType == jsonContentType:
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	return resp, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, r request) (*http.Request, error) {
+	url := fmt.Sprintf("%s%s", c.baseURL, r.path)
+
+	var body io.Reader
+	if r.body != nil {
+		var err error
+		body, err = r.body()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, url, body)
+	if err != nil {
+		if closer, ok := body.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if r.length > 0 {
+		req.ContentLength = r.length
+	}
+
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.token))
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	return req, nil
+}
+
 func (c *Client) Get(ctx context.Context, path string, result interface{}) error {
 	return c.do(ctx, http.MethodGet, path, nil, result)
 }
 
 func (c *Client) Post(ctx context.Context, path string, body interface{}, result interface{}) error {
-	var bodyReader io.Reader
+	var data []byte
 	if body != nil {
-		data, err := json.Marshal(body)
+		var err error
+		data, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(data)
 	}
-	return c.do(ctx, http.MethodPost, path, bodyReader, result)
+	return c.do(ctx, http.MethodPost, path, data, result)
 }
 
 func (c *Client) Put(ctx context.Context, path string, body interface{}, result interface{}) error {
@@ -88,7 +327,7 @@ func (c *Client) Put(ctx context.Context, path string, body interface{}, result
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
-	return c.do(ctx, http.MethodPut, path, bytes.NewReader(data), result)
+	return c.do(ctx, http.MethodPut, path, data, result)
 }
 
 func (c *Client) Patch(ctx context.Context, path string, body interface{}, result interface{}) error {
@@ -96,95 +335,440 @@ func (c *Client) Patch(ctx context.Context, path string, body interface{}, resul
 	if err != nil {
 		return fmt.Errorf("failed to marshal request body: %w", err)
 	}
-	return c.do(ctx, http.MethodPatch, path, bytes.NewReader(data), result)
+	return c.do(ctx, http.MethodPatch, path, data, result)
 }
 
 func (c *Client) Delete(ctx context.Context, path string) error {
 	return c.do(ctx, http.MethodDelete, path, nil, nil)
 }
 
-func (c *Client) UploadFile(ctx context.Context, path, filePath string, result interface{}) error {
-	file, err := os.Open(filePath)
+// Upload is a file sent as a multipart form by UploadFile.
+type Upload struct {
+	// Name is the file name the server stores.
+	Name string
+	// Size is the exact length of the content Open returns.
+	Size int64
+	// Open returns the content. It is called again for a retried attempt,
+	// so each attempt starts from the beginning.
+	Open func() (io.ReadCloser, error)
+	// Progress, if set, is called with the number of bytes sent so far.
+	Progress func(sent int64)
+}
+
+// FileUpload returns an Upload of the file at path, named after it.
+func FileUpload(path string) (*Upload, error) {
+	info, err := os.Stat(path)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer func() { _ = file.Close() }()
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("failed to open file: %s is not a regular file", path)
+	}
+	return &Upload{
+		Name: filepath.Base(path),
+		Size: info.Size(),
+		Open: func() (io.ReadCloser, error) {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file: %w", err)
+			}
+			return file, nil
+		},
+	}, nil
+}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+// UploadFile sends upload as the "file" field of a multipart form. The
+// form is written through a pipe while the request is sent, so the file is
+// never held in memory. Its size is known up front, so the request carries
+// a Content-Length rather than being chunked, which not every server
+// accepts. Uploads aren't bound by the client timeout, since large files
+// take longer; cancelling ctx still stops them.
+func (c *Client) UploadFile(ctx context.Context, path string, upload *Upload, result interface{}) error {
+	// The form around the file has a fixed length, measured by writing it
+	// without the content
+	var envelope countingWriter
+	form := multipart.NewWriter(&envelope)
+	if _, err := form.CreateFormFile("file", upload.Name); err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	boundary := form.Boundary()
 
-	part, err := writer.CreateFormFile("file", filepath.Base(filePath))
+	body := func() (io.Reader, error) {
+		content, err := upload.Open()
+		if err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			defer func() { _ = content.Close() }()
+			_ = pw.CloseWithError(writeForm(pw, boundary, upload, content))
+		}()
+		return pr, nil
+	}
+
+	resp, err := c.send(ctx, request{
+		method:      http.MethodPost,
+		path:        path,
+		body:        body,
+		contentType: form.FormDataContentType(),
+		length:      envelope.n + upload.Size,
+		streaming:   true,
+		summary:     fmt.Sprintf("file %s (%d bytes)", upload.Name, upload.Size),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
 	}
 
-	if _, err := io.Copy(part, file); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
+	return nil
+}
+
+// writeForm writes the multipart form holding content to w.
+func writeForm(w io.Writer, boundary string, upload *Upload, content io.Reader) error {
+	form := multipart.NewWriter(w)
+	if err := form.SetBoundary(boundary); err != nil {
+		return err
+	}
+	part, err := form.CreateFormFile("file", upload.Name)
+	if err != nil {
+		return err
 	}
 
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close multipart writer: %w", err)
+	var sent int64
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := content.Read(buf)
+		if n > 0 {
+			if _, werr := part.Write(buf[:n]); werr != nil {
+				return werr
+			}
+			sent += int64(n)
+			if upload.Progress != nil {
+				upload.Progress(sent)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", upload.Name, err)
+		}
+	}
+	if sent != upload.Size {
+		return fmt.Errorf("%s changed size while uploading (%d bytes, expected %d)", upload.Name, sent, upload.Size)
+	}
+	return form.Close()
+}
+
+// countingWriter discards what is written to it and counts the bytes.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Download configures DownloadFile.
+type Download struct {
+	// Size is the expected size, used for progress when the server sends
+	// no Content-Length; 0 if unknown.
+	Size int64
+	// SHA256, if set, is the expected hex digest of the file. A download
+	// that doesn't match is discarded.
+	SHA256 string
+	// Checksum makes DownloadFile return the file's SHA-256.
+	Checksum bool
+	// Progress, if set, is called with the number of bytes written so far,
+	// counting a resumed part, and the total, 0 if unknown.
+	Progress func(written, total int64)
+}
+
+// DownloadFile downloads path to outputPath. The content is written to
+// outputPath+".part" and renamed into place once complete, so an
+// interrupted download never leaves a truncated file under the final name.
+// A part left by an earlier attempt of the same download, as recorded in
+// outputPath+".part.json", is resumed with a Range request; the server may
+// send the whole file instead, as it does when the file changed since. A
+// connection lost mid-transfer is resumed the same way, up to the retry
+// policy's attempts.
+// It returns the hex SHA-256 of the file when opts asks for one.
+func (c *Client) DownloadFile(ctx context.Context, path, outputPath string, opts *Download) (string, error) {
+	if opts == nil {
+		opts = &Download{}
+	}
+	partial := outputPath + ".part"
+
+	for attempt := 1; ; attempt++ {
+		err := c.downloadPart(ctx, path, partial, opts)
+		if err == nil {
+			break
+		}
+		var lost *transferError
+		if !errors.As(err, &lost) || ctx.Err() != nil || attempt >= c.retry.MaxAttempts {
+			// Keep a part worth resuming, but not an empty one left by a
+			// request that failed outright
+			if info, statErr := os.Stat(partial); statErr == nil && info.Size() == 0 {
+				removePart(partial)
+			}
+			return "", err
+		}
+		wait := c.retry.backoff(attempt)
+		c.retry.notify(http.MethodGet, path, attempt, wait, err)
+		if err := sleep(ctx, wait); err != nil {
+			return "", fmt.Errorf("request failed: %w", err)
+		}
+	}
+
+	var sum string
+	if opts.Checksum || opts.SHA256 != "" {
+		var err error
+		if sum, err = fileSHA256(partial); err != nil {
+			return "", err
+		}
+		if opts.SHA256 != "" && !strings.EqualFold(sum, opts.SHA256) {
+			removePart(partial)
+			return "", exitcode.Wrap(exitcode.Validation, fmt.Errorf("checksum mismatch: expected SHA-256 %s, got %s", strings.ToLower(opts.SHA256), sum))
+		}
+	}
+
+	if err := os.Rename(partial, outputPath); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", outputPath, err)
+	}
+	_ = os.Remove(partInfoPath(partial))
+	return sum, nil
+}
+
+// transferError is a failure reading the response body, after which the
+// download can be resumed.
+type transferError struct {
+	err error
+}
+
+func (e *transferError) Error() string {
+	return fmt.Sprintf("download interrupted: %v", e.err)
+}
+
+func (e *transferError) Unwrap() error {
+	return e.err
+}
+
+// downloadPart fetches the rest of path into partial, resuming after the
+// bytes it already holds if they came from the same file.
+func (c *Client) downloadPart(ctx context.Context, path, partial string, opts *Download) error {
+	out, err := os.OpenFile(partial, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
+	defer func() { _ = out.Close() }()
 
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	offset, err := out.Seek(0, io.SeekEnd)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	restart := func() error {
+		offset = 0
+		if err := out.Truncate(0); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+		return nil
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.token))
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// A part of another file, such as an earlier download of a different
+	// asset to the same path, must not be continued
+	info := readPartInfo(partial)
+	if offset > 0 && (info == nil || info.Path != path || info.Size != opts.Size) {
+		if err := restart(); err != nil {
+			return err
+		}
+	}
 
-	resp, err := c.httpClient.Do(req)
+	r := request{method: http.MethodGet, path: path, streaming: true}
+	if offset > 0 {
+		r.header = http.Header{"Range": {fmt.Sprintf("bytes=%d-", offset)}}
+		// The server sends the whole file instead if it changed since
+		if validator := info.validator(); validator != "" {
+			r.header.Set("If-Range", validator)
+		}
+	}
+	resp, err := c.send(ctx, r)
+	if offset > 0 {
+		var apiErr *APIError
+		switch {
+		case err == nil && resp.StatusCode == http.StatusPartialContent && resumesAt(resp.Header.Get("Content-Range"), offset):
+			// Append the rest to the part
+		case err == nil && resp.StatusCode != http.StatusPartialContent:
+			// The server ignored the range and sent the whole file
+			if err := restart(); err != nil {
+				_ = resp.Body.Close()
+				return err
+			}
+		case err == nil, errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			// The part doesn't fit the file, so it can't be trusted;
+			// start over
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if err := restart(); err != nil {
+				return err
+			}
+			r.header = nil
+			resp, err = c.send(ctx, r)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if err := checkResponse(resp); err != nil {
-		return err
+	if offset == 0 {
+		info := &partInfo{
+			Path:         path,
+			Size:         opts.Size,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		}
+		if err := info.write(partial); err != nil {
+			return err
+		}
 	}
 
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+	total := opts.Size
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	written := offset
+	if opts.Progress != nil {
+		opts.Progress(written, total)
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, werr := out.Write(buf[:n]); werr != nil {
+				return fmt.Errorf("failed to write file: %w", werr)
+			}
+			written += int64(n)
+			if opts.Progress != nil {
+				opts.Progress(written, total)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return &transferError{err: err}
 		}
 	}
 
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
 	return nil
 }
 
-func (c *Client) DownloadFile(ctx context.Context, path, outputPath string) error {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
+// partInfo records where a ".part" file came from, in a file beside it,
+// so it is only resumed from the same file on the server.
+type partInfo struct {
+	Path         string `json:"path"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func partInfoPath(partial string) string {
+	return partial + ".json"
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// readPartInfo returns the record of partial, or nil if there is none.
+func readPartInfo(partial string) *partInfo {
+	data, err := os.ReadFile(partInfoPath(partial))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil
 	}
+	var info partInfo
+	if json.Unmarshal(data, &info) != nil {
+		return nil
+	}
+	return &info
+}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Token %s", c.token))
-
-	resp, err := c.httpClient.Do(req)
+func (p *partInfo) write(partial string) error {
+	data, err := json.Marshal(p)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	if err := os.WriteFile(partInfoPath(partial), data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
 
-	if err := checkResponse(resp); err != nil {
-		return err
+// validator returns the If-Range value for resuming the part: its strong
+// ETag, or else its Last-Modified date.
+func (p *partInfo) validator() string {
+	switch {
+	case p == nil:
+		return ""
+	case p.ETag != "" && !strings.HasPrefix(p.ETag, "W/"):
+		return p.ETag
 	}
+	return p.LastModified
+}
 
-	out, err := os.Create(outputPath)
+// removePart deletes partial and its record.
+func removePart(partial string) {
+	_ = os.Remove(partial)
+	_ = os.Remove(partInfoPath(partial))
+}
+
+// resumesAt reports whether a Content-Range header such as
+// "bytes 100-999/1000" describes a non-empty range starting at offset.
+func resumesAt(contentRange string, offset int64) bool {
+	spec, ok := strings.CutPrefix(contentRange, "bytes ")
+	if !ok {
+		return false
+	}
+	span, _, _ := strings.Cut(spec, "/")
+	first, last, ok := strings.Cut(span, "-")
+	if !ok {
+		return false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
 	if err != nil {
-		return fmt.Errorf("failed to create output file: %w", err)
+		return false
 	}
-	defer func() { _ = out.Close() }()
+	end, err := strconv.ParseInt(last, 10, 64)
+	return err == nil && start == offset && end >= start
+}
 
-	if _, err := io.Copy(out, resp.Body); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+// fileSHA256 returns the hex SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
+	defer func() { _ = file.Close() }()
 
-	return nil
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 func (c *Client) BuildURL(path string, params url.Values) string {
@@ -194,6 +778,31 @@ func (c *Client) BuildURL(path string, params url.Values) string {
 	return fmt.Sprintf("%s?%s", path, params.Encode())
 }
 
+// RelativePath converts an absolute URL returned by the server (such as a
+// pagination "next" link) into a path relative to the client's base URL.
+// Only the path and query are kept, so the request always goes to the
+// configured instance even when a reverse proxy reports a different host
+// or scheme.
+func (c *Client) RelativePath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	path := u.EscapedPath()
+	if base, err := url.Parse(c.baseURL); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.EscapedPath(), "/"))
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
 func checkResponse(resp *http.Response) error {
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		return nil
@@ -215,32 +824,42 @@ func checkResponse(resp *http.Response) error {
 		}
 	}
 
-	switch resp.StatusCode {
-	case http.StatusUnauthorized, http.StatusForbidden:
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
 		return &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    "Authentication failed. Check your API token.",
-			ExitCode:   3,
+			ExitCode:   exitcode.Auth,
 		}
-	case http.StatusNotFound:
+	case resp.StatusCode == http.StatusNotFound:
 		if errorMsg == "" {
 			errorMsg = "Resource not found"
 		}
 		return &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    errorMsg,
-			ExitCode:   4,
-		}
-	default:
-		if errorMsg == "" {
-			errorMsg = fmt.Sprintf("API request failed with status %d", resp.StatusCode)
-		}
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    errorMsg,
-			ExitCode:   1,
+			ExitCode:   exitcode.NotFound,
 		}
 	}
+
+	if errorMsg == "" {
+		errorMsg = fmt.Sprintf("API request failed with status %d", resp.StatusCode)
+	}
+
+	code := exitcode.General
+	switch {
+	case resp.StatusCode == http.StatusBadRequest, resp.StatusCode == http.StatusConflict,
+		resp.StatusCode == http.StatusUnprocessableEntity:
+		code = exitcode.Validation
+	case resp.StatusCode >= 500:
+		code = exitcode.Server
+	}
+
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    errorMsg,
+		ExitCode:   code,
+	}
 }
 
 type APIError struct {
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Idempotent requests
+// (GET, HEAD, PUT, DELETE) are retried on connection errors and on 429, 502,
+// 503 and 504 responses. Other methods are only retried when the request
+// never reached the server.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on every
+	// further attempt and is randomized ("full jitter").
+	BaseDelay time.Duration
+	// MaxWait caps a single backoff delay. A Retry-After header asking for
+	// longer than MaxWait is not honored and the error is returned instead.
+	MaxWait time.Duration
+	// OnRetry, if set, is called before sleeping ahead of each retry.
+	OnRetry func(method, path string, attempt int, wait time.Duration, reason error)
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxWait:     30 * time.Second,
+}
+
+// backoff returns a jittered exponential delay for the given attempt
+// number, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryPolicy.BaseDelay
+	}
+
+	ceiling := base << (attempt - 1)
+	if ceiling <= 0 || (p.MaxWait > 0 && ceiling > p.MaxWait) {
+		ceiling = p.MaxWait
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling))) + 1
+}
+
+// delay returns how long to wait before retrying after resp. It honors
+// Retry-After on 429 and 503 responses, and reports false when the server
+// asks for a longer wait than the policy allows.
+func (p RetryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxWait > 0 && wait > p.MaxWait {
+				return 0, false
+			}
+			return wait, true
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+func (p RetryPolicy) notify(method, path string, attempt int, wait time.Duration, reason error) {
+	if p.OnRetry != nil {
+		p.OnRetry(method, path, attempt, wait, reason)
+	}
+}
+
+// parseRetryAfter understands both forms of the header: a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
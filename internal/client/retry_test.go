@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxWait: time.Second}
+	tests := []struct {
+		attempt int
+		ceiling time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{40, time.Second},
+		{70, time.Second}, // the shift overflows
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			wait := policy.backoff(tt.attempt)
+			if wait <= 0 || wait > tt.ceiling {
+				t.Fatalf("backoff(%d) = %s, want in (0, %s]", tt.attempt, wait, tt.ceiling)
+			}
+		}
+	}
+}
+
+func TestBackoffDefaults(t *testing.T) {
+	wait := RetryPolicy{}.backoff(1)
+	if wait <= 0 || wait > DefaultRetryPolicy.BaseDelay {
+		t.Errorf("backoff without a base delay = %s, want in (0, %s]", wait, DefaultRetryPolicy.BaseDelay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"1.5", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %s, %v; want %s, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// A date is relative to now, so only check it roughly
+	future := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	got, ok := parseRetryAfter(future)
+	if !ok || got <= 5*time.Second || got > 10*time.Second {
+		t.Errorf("parseRetryAfter(%q) = %s, %v; want about 10s", future, got, ok)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxWait: 30 * time.Second}
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		want       time.Duration // 0 means a backoff delay
+		wantOK     bool
+	}{
+		{"429 honors Retry-After", http.StatusTooManyRequests, "2", 2 * time.Second, true},
+		{"503 honors Retry-After", http.StatusServiceUnavailable, "3", 3 * time.Second, true},
+		{"too long a wait gives up", http.StatusTooManyRequests, "60", 0, false},
+		{"502 ignores Retry-After", http.StatusBadGateway, "20", 0, true},
+		{"invalid Retry-After backs off", http.StatusServiceUnavailable, "later", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			resp.Header.Set("Retry-After", tt.retryAfter)
+			got, ok := policy.delay(1, resp)
+			if ok != tt.wantOK {
+				t.Fatalf("delay ok = %v, want %v", ok, tt.wantOK)
+			}
+			switch {
+			case !ok:
+			case tt.want != 0 && got != tt.want:
+				t.Errorf("delay = %s, want %s", got, tt.want)
+			case tt.want == 0 && (got <= 0 || got > policy.BaseDelay):
+				t.Errorf("delay = %s, want a backoff in (0, %s]", got, policy.BaseDelay)
+			}
+		})
+	}
+}
+
+// testClient returns a client for url that retries quickly, counting the
+// retries it announces.
+func testClient(url string, retries *atomic.Int32) *Client {
+	return New(url, "secret", WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxWait:     10 * time.Millisecond,
+		OnRetry: func(string, string, int, time.Duration, error) {
+			retries.Add(1)
+		},
+	}))
+}
+
+func TestRetryStatus(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		want   int32 // requests the server sees
+	}{
+		{http.MethodGet, http.StatusServiceUnavailable, 3},
+		{http.MethodGet, http.StatusBadGateway, 3},
+		{http.MethodDelete, http.StatusGatewayTimeout, 3},
+		{http.MethodGet, http.StatusInternalServerError, 1},
+		{http.MethodGet, http.StatusNotFound, 1},
+		{http.MethodPost, http.StatusServiceUnavailable, 1},
+		{http.MethodPatch, http.StatusTooManyRequests, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+http.StatusText(tt.status), func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			var retries atomic.Int32
+			c := testClient(server.URL, &retries)
+			if err := c.do(context.Background(), tt.method, "/api/bookmarks/", nil, nil); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("server saw %d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryRecovers(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+
+	var retries atomic.Int32
+	var result struct{ ID int }
+	if err := testClient(server.URL, &retries).Get(context.Background(), "/api/bookmarks/7/", &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != 7 || retries.Load() != 1 {
+		t.Errorf("got ID %d after %d retries, want 7 after 1", result.ID, retries.Load())
+	}
+}
+
+// TestRetryAfterHeadersWritten checks that a connection dropped after the
+// request was sent is retried for GET, but not for POST, which the server
+// may already have acted on.
+func TestRetryAfterHeadersWritten(t *testing.T) {
+	tests := []struct {
+		method string
+		want   int32
+	}{
+		{http.MethodGet, 3},
+		{http.MethodPost, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				conn, _, err := w.(http.Hijacker).Hijack()
+				if err == nil {
+					_ = conn.Close()
+				}
+			}))
+			defer server.Close()
+
+			var retries atomic.Int32
+			c := testClient(server.URL, &retries)
+			var body []byte
+			if tt.method == http.MethodPost {
+				body = []byte(`{"url": "https://example.com"}`)
+			}
+			if err := c.do(context.Background(), tt.method, "/api/bookmarks/", body, nil); err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := requests.Load(); got != tt.want {
+				t.Errorf("server saw %d requests, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRetryBeforeHeadersWritten checks that a POST that never reached the
+// server is retried.
+func TestRetryBeforeHeadersWritten(t *testing.T) {
+	// A listener that is closed again refuses connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + listener.Addr().String()
+	_ = listener.Close()
+
+	var retries atomic.Int32
+	c := testClient(url, &retries)
+	if err := c.Post(context.Background(), "/api/bookmarks/", map[string]string{"url": "https://example.com"}, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := retries.Load(); got != 2 {
+		t.Errorf("POST was retried %d times, want 2", got)
+	}
+}
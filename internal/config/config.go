@@ -4,32 +4,145 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Output formats, selected with --output (or --json, --plain, --format) or
+// defaults.output_format.
+const (
+	FormatTable    = "table"
+	FormatPlain    = "plain"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatTemplate = "template"
+)
+
+// OutputFormats lists the formats that can be chosen by name.
+var OutputFormats = []string{FormatTable, FormatPlain, FormatJSON, FormatNDJSON, FormatYAML, FormatCSV, FormatTSV}
+
+// ParseOutputFormat validates a format name. "auto" and "" select the
+// table.
+func ParseOutputFormat(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		return FormatTable, nil
+	}
+	for _, format := range OutputFormats {
+		if name == format {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (valid: %s)", name, strings.Join(OutputFormats, ", "))
+}
+
+// Sources of a setting, as reported by "config show".
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "environment"
+	SourceFile    = "config file"
+	SourceProfile = "profile"
+	SourceUnset   = "not set"
+)
+
 type Config struct {
 	URL         string
 	Token       string
-	OutputJSON  bool
-	OutputPlain bool
-	NoColor     bool
-	Quiet       bool
-	Verbose     bool
+	TokenSource `mapstructure:",squash"`
+
+	NoColor bool
+	Quiet   bool
+	Verbose bool
+
+	// DryRun prints changing requests instead of sending them
+	DryRun bool
+
+	// Offline answers requests from the local mirror instead of the server
+	Offline bool
+
+	// Output is the selected output format, one of the Format constants
+	Output string
+
+	// OutputTemplate is the text of the --format template, if any
+	OutputTemplate string
+
+	// Columns and Sort are the --columns and --sort flags, which pick and
+	// order the fields of list output
+	Columns []string
+	Sort    string
 
 	// Default settings from config file
 	Defaults struct {
 		BookmarkLimit int    `mapstructure:"bookmark_limit"`
 		OutputFormat  string `mapstructure:"output_format"`
 	}
+
+	// Retry policy for failed requests
+	Retry struct {
+		MaxAttempts int           `mapstructure:"max_attempts"`
+		MaxWait     time.Duration `mapstructure:"max_wait"`
+	}
+
+	// Named server profiles; names are case-insensitive
+	Profiles       map[string]Profile `mapstructure:"profiles"`
+	CurrentProfile string             `mapstructure:"current_profile"`
+
+	// Profile is the name of the active profile, empty when none is
+	// selected. It may name a profile that does not exist; see
+	// MissingProfile.
+	Profile string `mapstructure:"-"`
+
+	// File is the config file that was read, empty if there was none.
+	File string `mapstructure:"-"`
+
+	// Sources records where "url", "token" and "profile" came from.
+	Sources map[string]string `mapstructure:"-"`
+
+	// tokenProfile is the profile TokenSource belongs to, which names
+	// its keyring entry.
+	tokenProfile string
+}
+
+// HumanOutput reports whether output is meant for people rather than
+// scripts, i.e. no machine-readable format was requested.
+func (c *Config) HumanOutput() bool {
+	return c.Output == FormatTable || c.Output == ""
+}
+
+// Profile holds the connection settings of one linkding instance.
+type Profile struct {
+	URL         string `mapstructure:"url"`
+	Token       string `mapstructure:"token"`
+	TokenSource `mapstructure:",squash"`
+}
+
+// MissingProfile reports whether a profile was selected that the config
+// file does not define.
+func (c *Config) MissingProfile() bool {
+	if c.Profile == "" {
+		return false
+	}
+	_, ok := c.Profiles[c.Profile]
+	return !ok
 }
 
-func Load(cfgFile string) (*Config, error) {
+// Load reads the configuration from cfgFile (or the default location),
+// then applies the selected profile and environment variables. profile
+// selects a profile by name, taking precedence over LINKDING_PROFILE and
+// current_profile; pass "" to use those.
+func Load(cfgFile, profile string) (*Config, error) {
 	v := viper.New()
 
 	// Set defaults
 	v.SetDefault("defaults.bookmark_limit", 100)
 	v.SetDefault("defaults.output_format", "auto")
+	v.SetDefault("retry.max_attempts", 3)
+	v.SetDefault("retry.max_wait", "30s")
 
 	// Environment variables
 	v.SetEnvPrefix("LINKDING")
@@ -61,12 +174,52 @@ func Load(cfgFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	// Environment variables take precedence over config file
-	if envURL := v.GetString("url"); envURL != "" {
-		cfg.URL = envURL
+	cfg.File = v.ConfigFileUsed()
+	cfg.Sources = map[string]string{
+		"url":     SourceUnset,
+		"token":   SourceUnset,
+		"profile": SourceUnset,
+	}
+	if cfg.URL != "" {
+		cfg.Sources["url"] = SourceFile
+	}
+	if cfg.Token != "" {
+		cfg.Sources["token"] = SourceFile
+	}
+
+	// Select the profile: flag, then environment, then config file
+	switch {
+	case profile != "":
+		cfg.Profile, cfg.Sources["profile"] = profile, SourceFlag
+	case os.Getenv("LINKDING_PROFILE") != "":
+		cfg.Profile, cfg.Sources["profile"] = os.Getenv("LINKDING_PROFILE"), SourceEnv
+	case cfg.CurrentProfile != "":
+		cfg.Profile, cfg.Sources["profile"] = cfg.CurrentProfile, SourceFile
+	}
+	cfg.Profile = strings.ToLower(cfg.Profile)
+
+	// Profile values take precedence over top-level ones. A profile that
+	// configures its token in any way replaces the top-level token setup.
+	if p, ok := cfg.Profiles[cfg.Profile]; ok {
+		if p.URL != "" {
+			cfg.URL, cfg.Sources["url"] = p.URL, SourceProfile
+		}
+		if p.Token != "" || p.TokenSource.IsSet() {
+			cfg.Token, cfg.TokenSource = p.Token, p.TokenSource
+			cfg.tokenProfile = cfg.Profile
+			cfg.Sources["token"] = SourceUnset
+			if p.Token != "" {
+				cfg.Sources["token"] = SourceProfile
+			}
+		}
+	}
+
+	// Environment variables take precedence over the config file
+	if envURL := os.Getenv("LINKDING_URL"); envURL != "" {
+		cfg.URL, cfg.Sources["url"] = envURL, SourceEnv
 	}
-	if envToken := v.GetString("token"); envToken != "" {
-		cfg.Token = envToken
+	if envToken := os.Getenv("LINKDING_TOKEN"); envToken != "" {
+		cfg.Token, cfg.Sources["token"] = envToken, SourceEnv
 	}
 
 	// Check NO_COLOR environment variable
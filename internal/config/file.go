@@ -0,0 +1,95 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads a config file as a generic map, so commands that edit it
+// keep settings they don't know about. A missing file yields an empty map.
+func ReadFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]interface{}{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return settings, nil
+}
+
+// WriteFile writes settings to path as YAML. The file holds API tokens, so
+// it is only readable by the owner, and it is replaced atomically so a
+// failed write never leaves a truncated config behind.
+func WriteFile(path string, settings map[string]interface{}) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(settings); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set config file permissions: %w", err)
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// SetProfile adds or replaces a profile in settings as read by ReadFile.
+func SetProfile(settings map[string]interface{}, name string, p Profile) {
+	profiles, _ := settings["profiles"].(map[string]interface{})
+	if profiles == nil {
+		profiles = map[string]interface{}{}
+		settings["profiles"] = profiles
+	}
+
+	entry := map[string]interface{}{"url": p.URL}
+	if p.Token != "" {
+		entry["token"] = p.Token
+	}
+	if p.Command != "" {
+		entry["token_command"] = p.Command
+	}
+	if p.File != "" {
+		entry["token_file"] = p.File
+	}
+	if p.Keyring {
+		entry["token_keyring"] = true
+	}
+	profiles[name] = entry
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringService is the service name tokens are stored under in the OS
+// keyring (Secret Service, macOS Keychain or Windows Credential Manager).
+const KeyringService = "clinkding"
+
+// Token sources besides the plain-text token key, as recorded in
+// Config.Sources["token"].
+const (
+	SourceTokenCommand = "token_command"
+	SourceTokenFile    = "token_file"
+	SourceKeyring      = "keyring"
+)
+
+// TokenSource tells clinkding where to fetch the API token from instead of
+// reading it from the config file. It is only consulted when no plain-text
+// token is configured. At most one field should be set; if several are,
+// the command wins over the file, and the file over the keyring.
+type TokenSource struct {
+	// Command is run through the shell and its trimmed stdout is the
+	// token, e.g. "pass show linkding".
+	Command string `mapstructure:"token_command"`
+
+	// File is read and its trimmed contents are the token. A leading ~ is
+	// expanded to the home directory.
+	File string `mapstructure:"token_file"`
+
+	// Keyring reads the token from the OS keyring.
+	Keyring bool `mapstructure:"token_keyring"`
+}
+
+// IsSet reports whether any backend is configured.
+func (s TokenSource) IsSet() bool {
+	return s.Command != "" || s.File != "" || s.Keyring
+}
+
+// KeyringAccount returns the keyring account a profile's token is stored
+// under; the top-level configuration uses "default".
+func KeyringAccount(profile string) string {
+	if profile == "" {
+		return "default"
+	}
+	return profile
+}
+
+// ResolveToken fetches the token from the configured backend when no
+// token has been set by a flag, the environment or the config file. It is
+// separate from Load so a token given on the command line never runs the
+// token command or touches the keyring.
+func (c *Config) ResolveToken() error {
+	if c.Token != "" || !c.TokenSource.IsSet() {
+		return nil
+	}
+
+	src := c.TokenSource
+	var (
+		token  string
+		source string
+		err    error
+	)
+	switch {
+	case src.Command != "":
+		token, err = runTokenCommand(src.Command)
+		source = SourceTokenCommand
+	case src.File != "":
+		token, err = readTokenFile(src.File)
+		source = SourceTokenFile
+	default:
+		token, err = KeyringGet(KeyringAccount(c.tokenProfile))
+		source = SourceKeyring
+	}
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("%s returned an empty token", source)
+	}
+
+	c.Token = token
+	c.Sources["token"] = source
+	return nil
+}
+
+func runTokenCommand(command string) (string, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+
+	// Password managers may prompt for a passphrase
+	var stdout bytes.Buffer
+	c.Stdin = os.Stdin
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("token_command %q failed: %w", command, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func readTokenFile(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token_file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// KeyringGet reads a token from the OS keyring.
+func KeyringGet(account string) (string, error) {
+	token, err := keyring.Get(KeyringService, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("no token for %q in the system keyring. Run: clinkding config init", account)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read token from the system keyring: %w", err)
+	}
+	return token, nil
+}
+
+// KeyringSet stores a token in the OS keyring, replacing any existing one.
+func KeyringSet(account, token string) error {
+	if err := keyring.Set(KeyringService, account, token); err != nil {
+		return fmt.Errorf("failed to store token in the system keyring: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Package exitcode defines the process exit codes clinkding guarantees to
+// scripts and maps errors onto them.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+const (
+	OK          = 0   // Success
+	General     = 1   // Unclassified error
+	Usage       = 2   // Invalid flags, arguments or subcommand
+	Auth        = 3   // Missing or rejected API token (401/403)
+	NotFound    = 4   // Resource does not exist (404)
+	Validation  = 5   // Request rejected as invalid or conflicting (400/409/422)
+	Server      = 6   // linkding or a proxy in front of it failed (5xx)
+	Network     = 7   // Connection, DNS or timeout failure
+	Config      = 8   // Configuration missing or unreadable
+	Aborted     = 9   // User declined a confirmation prompt
+	Interrupted = 130 // Interrupted by a signal (Ctrl-C)
+)
+
+// Error attaches an exit code to an error.
+type Error struct {
+	Code int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) GetExitCode() int {
+	return e.Code
+}
+
+// ErrAborted is returned when the user answers "no" to a confirmation.
+var ErrAborted = &Error{Code: Aborted, Err: errors.New("aborted by user")}
+
+// Wrap attaches code to err. It returns nil if err is nil.
+func Wrap(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Usagef formats a usage error. Like fmt.Errorf, it supports %w.
+func Usagef(format string, args ...interface{}) error {
+	return Wrap(Usage, fmt.Errorf(format, args...))
+}
+
+// Configf formats a configuration error. Like fmt.Errorf, it supports %w.
+func Configf(format string, args ...interface{}) error {
+	return Wrap(Config, fmt.Errorf(format, args...))
+}
+
+// FromError returns the exit code for err. Errors carrying their own code
+// (anything with a GetExitCode method, such as *client.APIError) win;
+// otherwise cancellation and network failures are recognized, and
+// everything else is a general error.
+func FromError(err error) int {
+	if err == nil {
+		return OK
+	}
+
+	var coded interface{ GetExitCode() int }
+	if errors.As(err, &coded) {
+		return coded.GetExitCode()
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return Interrupted
+	}
+
+	// Match concrete types: syscall.Errno also satisfies net.Error, which
+	// would misreport local file errors as network failures.
+	var urlErr *url.Error
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return Network
+	}
+
+	return General
+}
+
+// Kind returns a stable, machine-readable name for an exit code.
+func Kind(code int) string {
+	switch code {
+	case OK:
+		return "ok"
+	case Usage:
+		return "usage"
+	case Auth:
+		return "auth"
+	case NotFound:
+		return "not_found"
+	case Validation:
+		return "validation"
+	case Server:
+		return "server"
+	case Network:
+		return "network"
+	case Config:
+		return "config"
+	case Aborted:
+		return "aborted"
+	case Interrupted:
+		return "interrupted"
+	default:
+		return "error"
+	}
+}
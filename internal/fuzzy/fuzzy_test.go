@@ -0,0 +1,31 @@
+package fuzzy
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"", "", 2, 0},
+		{"search", "search", 2, 0},
+		{"search", "serach", 2, 2},
+		{"search", "searc", 1, 1},
+		{"search", "saerch", 1, 2}, // over the limit
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+		{"kitten", "sitting", 1, 2},
+		{"go", "golang", 2, 3}, // lengths alone rule it out
+		{"abc", "abcdef", 2, 3},
+		{"résumé", "resume", 2, 2},
+		{"café", "cafe", 1, 1},
+		{"naïve", "naive", 0, 1},
+		{"日本語", "日本", 1, 1},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("Distance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Package mirror keeps a local, read-only copy of a linkding instance's
+// bookmarks, tags and bundles in a bbolt database, so they can be listed
+// without a connection to the server.
+package mirror
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// StaleAfter is how old the last sync may be before commands answering
+// from the mirror warn about it.
+const StaleAfter = 24 * time.Hour
+
+// FullSyncAfter is how old the last full sync may be before a sync copies
+// everything again. Incremental syncs only notice deletions that lower
+// the bookmark count, so this bounds how long others linger.
+const FullSyncAfter = 7 * StaleAfter
+
+// lockTimeout is how long Open waits for a sync running in another
+// process to release the database.
+const lockTimeout = 2 * time.Second
+
+var (
+	metaBucket      = []byte("meta")
+	bookmarksBucket = []byte("bookmarks")
+	tagsBucket      = []byte("tags")
+	bundlesBucket   = []byte("bundles")
+
+	metaKey = []byte("meta")
+)
+
+// ErrNotSynced is returned by OpenReadOnly when no mirror exists yet.
+var ErrNotSynced = errors.New("no local mirror")
+
+// Meta describes the state of a mirror.
+type Meta struct {
+	// URL is the linkding instance the mirror copies.
+	URL string `json:"url"`
+	// LastSync and LastFullSync are local times of the last syncs.
+	LastSync     time.Time `json:"last_sync"`
+	LastFullSync time.Time `json:"last_full_sync"`
+	// Watermark is the latest modification date of any mirrored bookmark,
+	// on the server's clock. The next sync asks for bookmarks modified
+	// since then.
+	Watermark time.Time `json:"watermark"`
+}
+
+// Mirror is an open mirror database.
+type Mirror struct {
+	db *bolt.DB
+}
+
+// Path returns the location of the mirror for a config profile, in the
+// user's cache directory.
+func Path(profile string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return filepath.Join(dir, "clinkding", "mirror-"+profile+".db"), nil
+}
+
+// Open opens the mirror at path for syncing, creating it if needed.
+func Open(path string) (*Mirror, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, openError(path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{metaBucket, bookmarksBucket, tagsBucket, bundlesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize mirror: %w", err)
+	}
+	return &Mirror{db: db}, nil
+}
+
+// OpenReadOnly opens an existing mirror for reading. It returns
+// ErrNotSynced if there is none.
+func OpenReadOnly(path string) (*Mirror, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, ErrNotSynced
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: lockTimeout})
+	if err != nil {
+		return nil, openError(path, err)
+	}
+	return &Mirror{db: db}, nil
+}
+
+func openError(path string, err error) error {
+	if errors.Is(err, bolt.ErrTimeout) {
+		return fmt.Errorf("mirror %s is in use; is a sync running?", path)
+	}
+	return fmt.Errorf("failed to open mirror %s: %w", path, err)
+}
+
+func (m *Mirror) Close() error {
+	return m.db.Close()
+}
+
+// Meta returns the mirror's state. A mirror that was never synced has a
+// zero Meta.
+func (m *Mirror) Meta() (Meta, error) {
+	var meta Meta
+	err := m.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(metaBucket)
+		if b == nil {
+			return nil
+		}
+		if data := b.Get(metaKey); data != nil {
+			return json.Unmarshal(data, &meta)
+		}
+		return nil
+	})
+	if err != nil {
+		return Meta{}, fmt.Errorf("failed to read mirror: %w", err)
+	}
+	return meta, nil
+}
+
+// Bookmarks returns every mirrored bookmark, active and archived, by ID.
+func (m *Mirror) Bookmarks() ([]models.Bookmark, error) {
+	return all[models.Bookmark](m.db, bookmarksBucket)
+}
+
+// Bookmark returns the bookmark with the given ID, or nil if the mirror
+// has none.
+func (m *Mirror) Bookmark(id int) (*models.Bookmark, error) {
+	return one[models.Bookmark](m.db, bookmarksBucket, id)
+}
+
+// BookmarkIDs returns the IDs of every mirrored bookmark.
+func (m *Mirror) BookmarkIDs() (map[int]bool, error) {
+	ids := make(map[int]bool)
+	err := m.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bookmarksBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, _ []byte) error {
+			ids[int(binary.BigEndian.Uint64(k))] = true
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror: %w", err)
+	}
+	return ids, nil
+}
+
+// Tags returns every mirrored tag by ID.
+func (m *Mirror) Tags() ([]models.Tag, error) {
+	return all[models.Tag](m.db, tagsBucket)
+}
+
+// Tag returns the tag with the given ID, or nil if the mirror has none.
+func (m *Mirror) Tag(id int) (*models.Tag, error) {
+	return one[models.Tag](m.db, tagsBucket, id)
+}
+
+// Bundles returns every mirrored bundle by ID.
+func (m *Mirror) Bundles() ([]models.Bundle, error) {
+	return all[models.Bundle](m.db, bundlesBucket)
+}
+
+// Bundle returns the bundle with the given ID, or nil if the mirror has
+// none.
+func (m *Mirror) Bundle(id int) (*models.Bundle, error) {
+	return one[models.Bundle](m.db, bundlesBucket, id)
+}
+
+// Snapshot is the result of one sync, written by Save in a single
+// transaction so readers never see half of it.
+type Snapshot struct {
+	Meta Meta
+	// Full replaces every bookmark with Bookmarks; otherwise Bookmarks
+	// are added or updated and the rest kept.
+	Full      bool
+	Bookmarks []models.Bookmark
+	// Tags and Bundles always replace the mirrored ones.
+	Tags    []models.Tag
+	Bundles []models.Bundle
+}
+
+// Save writes a sync's result.
+func (m *Mirror) Save(s *Snapshot) error {
+	err := m.db.Update(func(tx *bolt.Tx) error {
+		if s.Full {
+			if err := recreate(tx, bookmarksBucket); err != nil {
+				return err
+			}
+		}
+		if err := putAll(tx.Bucket(bookmarksBucket), s.Bookmarks, func(b models.Bookmark) int { return b.ID }); err != nil {
+			return err
+		}
+
+		if err := recreate(tx, tagsBucket); err != nil {
+			return err
+		}
+		if err := putAll(tx.Bucket(tagsBucket), s.Tags, func(t models.Tag) int { return t.ID }); err != nil {
+			return err
+		}
+
+		if err := recreate(tx, bundlesBucket); err != nil {
+			return err
+		}
+		if err := putAll(tx.Bucket(bundlesBucket), s.Bundles, func(b models.Bundle) int { return b.ID }); err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(s.Meta)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(metaBucket).Put(metaKey, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write mirror: %w", err)
+	}
+	return nil
+}
+
+func recreate(tx *bolt.Tx, name []byte) error {
+	if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+		return err
+	}
+	_, err := tx.CreateBucket(name)
+	return err
+}
+
+func putAll[T any](b *bolt.Bucket, items []T, id func(T) int) error {
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key(id(item)), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func all[T any](db *bolt.DB, bucket []byte) ([]T, error) {
+	items := []T{}
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, data []byte) error {
+			var item T
+			if err := json.Unmarshal(data, &item); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror: %w", err)
+	}
+	return items, nil
+}
+
+func one[T any](db *bolt.DB, bucket []byte, id int) (*T, error) {
+	var item *T
+	err := db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		if b == nil {
+			return nil
+		}
+		data := b.Get(key(id))
+		if data == nil {
+			return nil
+		}
+		item = new(T)
+		return json.Unmarshal(data, item)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror: %w", err)
+	}
+	return item, nil
+}
+
+// key encodes an ID so keys sort numerically.
+func key(id int) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(id))
+	return k
+}
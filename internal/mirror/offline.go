@@ -0,0 +1,259 @@
+package mirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// defaultPageSize is linkding's page size when no limit is given.
+const defaultPageSize = 100
+
+var (
+	bookmarkPath = regexp.MustCompile(`^/api/bookmarks/(\d+)/$`)
+	tagPath      = regexp.MustCompile(`^/api/tags/(\d+)/$`)
+	bundlePath   = regexp.MustCompile(`^/api/bundles/(\d+)/$`)
+)
+
+// Transport returns a RoundTripper answering linkding API reads from the
+// mirror, as the server at baseURL would. Bookmark searches are matched
+// locally, so they only approximate the server's. Requests that change
+// data, or read anything else, fail with a usage error.
+func (m *Mirror) Transport(baseURL string) http.RoundTripper {
+	base, _ := url.Parse(baseURL)
+	prefix := ""
+	if base != nil {
+		prefix = strings.TrimSuffix(base.EscapedPath(), "/")
+	}
+	return &transport{mirror: m, baseURL: strings.TrimSuffix(baseURL, "/"), prefix: prefix}
+}
+
+type transport struct {
+	mirror  *Mirror
+	baseURL string
+	prefix  string
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path := strings.TrimPrefix(req.URL.EscapedPath(), t.prefix)
+	if req.Method != http.MethodGet {
+		return nil, exitcode.Usagef("%s %s needs the server; --offline only reads bookmarks, tags and bundles", req.Method, path)
+	}
+	query := req.URL.Query()
+
+	var (
+		body interface{}
+		err  error
+	)
+	switch {
+	case path == "/api/bookmarks/" || path == "/api/bookmarks/archived/":
+		body, err = t.listBookmarks(path, query, path == "/api/bookmarks/archived/")
+	case path == "/api/tags/":
+		var tags []models.Tag
+		if tags, err = t.mirror.Tags(); err == nil {
+			body = t.page(path, query, tags)
+		}
+	case path == "/api/bundles/":
+		var bundles []models.Bundle
+		if bundles, err = t.mirror.Bundles(); err == nil {
+			sort.SliceStable(bundles, func(i, j int) bool { return bundles[i].Order < bundles[j].Order })
+			body = t.page(path, query, bundles)
+		}
+	case bookmarkPath.MatchString(path):
+		body, err = found(t.mirror.Bookmark(pathID(bookmarkPath, path)))
+	case tagPath.MatchString(path):
+		body, err = found(t.mirror.Tag(pathID(tagPath, path)))
+	case bundlePath.MatchString(path):
+		body, err = found(t.mirror.Bundle(pathID(bundlePath, path)))
+	default:
+		return nil, exitcode.Usagef("%s isn't in the local mirror; --offline only reads bookmarks, tags and bundles", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := http.StatusOK
+	if body == nil {
+		status = http.StatusNotFound
+		body = map[string]string{"detail": "Not found."}
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// listBookmarks applies the list filters linkding supports, newest first.
+func (t *transport) listBookmarks(path string, query url.Values, archived bool) (interface{}, error) {
+	bookmarks, err := t.mirror.Bookmarks()
+	if err != nil {
+		return nil, err
+	}
+
+	modifiedSince, err := parseTime(query.Get("modified_since"))
+	if err != nil {
+		return nil, exitcode.Usagef("invalid modified_since: %w", err)
+	}
+	addedSince, err := parseTime(query.Get("added_since"))
+	if err != nil {
+		return nil, exitcode.Usagef("invalid added_since: %w", err)
+	}
+	var bundle *models.Bundle
+	if id := query.Get("bundle"); id != "" {
+		bundleID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, exitcode.Usagef("invalid bundle: %s", id)
+		}
+		if bundle, err = t.mirror.Bundle(bundleID); err != nil {
+			return nil, err
+		}
+		if bundle == nil {
+			return nil, exitcode.Wrap(exitcode.NotFound, fmt.Errorf("bundle #%d isn't in the local mirror", bundleID))
+		}
+	}
+	search := parseQuery(query.Get("q"))
+
+	matches := []models.Bookmark{}
+	for _, b := range bookmarks {
+		switch {
+		case b.IsArchived != archived:
+		case !modifiedSince.IsZero() && b.DateModified.Before(modifiedSince):
+		case !addedSince.IsZero() && b.DateAdded.Before(addedSince):
+		case bundle != nil && !inBundle(b, bundle):
+		case !search.matches(b):
+		default:
+			matches = append(matches, b)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].DateAdded.Equal(matches[j].DateAdded) {
+			return matches[i].DateAdded.After(matches[j].DateAdded)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+	return t.page(path, query, matches), nil
+}
+
+// page cuts one page out of items by the limit and offset parameters,
+// with next and previous links like the server's.
+func (t *transport) page(path string, query url.Values, items interface{}) interface{} {
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	offset = max(offset, 0)
+
+	link := func(offset int) *string {
+		params := url.Values{}
+		for k, v := range query {
+			params[k] = v
+		}
+		params.Set("limit", strconv.Itoa(limit))
+		if offset > 0 {
+			params.Set("offset", strconv.Itoa(offset))
+		} else {
+			params.Del("offset")
+		}
+		s := t.baseURL + path + "?" + params.Encode()
+		return &s
+	}
+
+	switch items := items.(type) {
+	case []models.Bookmark:
+		list := models.BookmarkList{Count: len(items)}
+		list.Results, list.Next, list.Previous = window(items, offset, limit, link)
+		return list
+	case []models.Tag:
+		list := models.TagList{Count: len(items)}
+		list.Results, list.Next, list.Previous = window(items, offset, limit, link)
+		return list
+	case []models.Bundle:
+		list := models.BundleList{Count: len(items)}
+		list.Results, list.Next, list.Previous = window(items, offset, limit, link)
+		return list
+	}
+	return nil
+}
+
+func window[T any](items []T, offset, limit int, link func(int) *string) (results []T, next, previous *string) {
+	start := min(offset, len(items))
+	end := min(start+limit, len(items))
+	results = items[start:end]
+	if results == nil {
+		results = []T{}
+	}
+	if end < len(items) {
+		next = link(end)
+	}
+	if start > 0 {
+		previous = link(max(start-limit, 0))
+	}
+	return results, next, previous
+}
+
+// found turns a missing item into a nil body, answered with a 404.
+func found[T any](item *T, err error) (interface{}, error) {
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func pathID(pattern *regexp.Regexp, path string) int {
+	id, _ := strconv.Atoi(pattern.FindStringSubmatch(path)[1])
+	return id
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// inBundle reports whether b matches a bundle's search and tag criteria.
+func inBundle(b models.Bookmark, bundle *models.Bundle) bool {
+	if !parseQuery(bundle.Search).matches(b) {
+		return false
+	}
+	if anyTags := strings.Fields(bundle.AnyTags); len(anyTags) > 0 && !hasAnyTag(b, anyTags) {
+		return false
+	}
+	for _, tag := range strings.Fields(bundle.AllTags) {
+		if !hasAnyTag(b, []string{tag}) {
+			return false
+		}
+	}
+	return !hasAnyTag(b, strings.Fields(bundle.ExcludedTags))
+}
+
+func hasAnyTag(b models.Bookmark, tags []string) bool {
+	for _, have := range b.TagNames {
+		for _, want := range tags {
+			if strings.EqualFold(have, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
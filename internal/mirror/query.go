@@ -0,0 +1,87 @@
+package mirror
+
+import (
+	"strings"
+
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// searchQuery is a linkding search: words and quoted phrases that must
+// all appear in a bookmark's title, description, notes, URL or tags,
+// "#tag" terms naming tags it must carry, and the "!unread" and
+// "!untagged" filters.
+type searchQuery struct {
+	terms    []string
+	tags     []string
+	unread   bool
+	untagged bool
+}
+
+func parseQuery(q string) searchQuery {
+	var query searchQuery
+	for _, term := range splitTerms(q) {
+		switch {
+		case term == "!unread":
+			query.unread = true
+		case term == "!untagged":
+			query.untagged = true
+		case strings.HasPrefix(term, "#") && len(term) > 1:
+			query.tags = append(query.tags, term[1:])
+		default:
+			query.terms = append(query.terms, strings.ToLower(term))
+		}
+	}
+	return query
+}
+
+// splitTerms splits q at whitespace, keeping quoted phrases together.
+func splitTerms(q string) []string {
+	var terms []string
+	for {
+		q = strings.TrimSpace(q)
+		if q == "" {
+			return terms
+		}
+		if q[0] == '"' {
+			if phrase, rest, ok := strings.Cut(q[1:], `"`); ok {
+				if phrase = strings.TrimSpace(phrase); phrase != "" {
+					terms = append(terms, phrase)
+				}
+				q = rest
+				continue
+			}
+			q = q[1:]
+		}
+		end := strings.IndexAny(q, " \t\n")
+		if end < 0 {
+			end = len(q)
+		}
+		terms = append(terms, q[:end])
+		q = q[end:]
+	}
+}
+
+func (q searchQuery) matches(b models.Bookmark) bool {
+	if q.unread && !b.Unread {
+		return false
+	}
+	if q.untagged && len(b.TagNames) > 0 {
+		return false
+	}
+	for _, tag := range q.tags {
+		if !hasAnyTag(b, []string{tag}) {
+			return false
+		}
+	}
+	if len(q.terms) == 0 {
+		return true
+	}
+
+	text := strings.ToLower(strings.Join([]string{b.Title, b.Description, b.Notes, b.URL, strings.Join(b.TagNames, " ")}, "\n"))
+	for _, term := range q.terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
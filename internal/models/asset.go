@@ -5,6 +5,7 @@ import "time"
 type Asset struct {
 	ID          int       `json:"id"`
 	BookmarkID  int       `json:"bookmark"`
+	AssetType   string    `json:"asset_type"`
 	File        string    `json:"file"`
 	DisplayName string    `json:"display_name"`
 	FileSize    int64     `json:"file_size"`
@@ -34,14 +34,21 @@ type BookmarkCreate struct {
 	IsArchived  bool     `json:"is_archived,omitempty"`
 	Unread      bool     `json:"unread,omitempty"`
 	Shared      bool     `json:"shared,omitempty"`
+
+	// DateAdded is sent for imports; linkding versions that treat the
+	// field as read-only ignore it and use the creation time.
+	DateAdded time.Time `json:"date_added,omitzero"`
 }
 
+// BookmarkUpdate is a partial update: nil fields are left unchanged, so a
+// field can also be cleared by pointing it at an empty string. TagNames
+// works the same way; an empty but non-nil slice removes every tag.
 type BookmarkUpdate struct {
-	URL         string   `json:"url,omitempty"`
-	Title       string   `json:"title,omitempty"`
-	Description string   `json:"description,omitempty"`
-	Notes       string   `json:"notes,omitempty"`
-	TagNames    []string `json:"tag_names,omitempty"`
+	URL         *string  `json:"url,omitempty"`
+	Title       *string  `json:"title,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Notes       *string  `json:"notes,omitempty"`
+	TagNames    []string `json:"tag_names,omitzero"`
 	Unread      *bool    `json:"unread,omitempty"`
 	Shared      *bool    `json:"shared,omitempty"`
 }
@@ -2,11 +2,19 @@ package models
 
 import "time"
 
+// Bundle is a saved search. A bookmark belongs to a bundle if it matches
+// the search, carries at least one of AnyTags and all of AllTags, and none
+// of ExcludedTags. The tag fields are space-separated tag names.
 type Bundle struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	DateAdded   time.Time `json:"date_added"`
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Search       string    `json:"search"`
+	AnyTags      string    `json:"any_tags"`
+	AllTags      string    `json:"all_tags"`
+	ExcludedTags string    `json:"excluded_tags"`
+	Order        int       `json:"order"`
+	DateAdded    time.Time `json:"date_added"`
 }
 
 type BundleList struct {
@@ -17,11 +25,23 @@ type BundleList struct {
 }
 
 type BundleCreate struct {
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Search       string `json:"search,omitempty"`
+	AnyTags      string `json:"any_tags,omitempty"`
+	AllTags      string `json:"all_tags,omitempty"`
+	ExcludedTags string `json:"excluded_tags,omitempty"`
+	Order        *int   `json:"order,omitempty"`
 }
 
+// BundleUpdate sends only the fields that are set. The description,
+// search and tag fields are pointers so they can be cleared.
 type BundleUpdate struct {
-	Name        string `json:"name,omitempty"`
-	Description string `json:"description,omitempty"`
+	Name         string  `json:"name,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	Search       *string `json:"search,omitempty"`
+	AnyTags      *string `json:"any_tags,omitempty"`
+	AllTags      *string `json:"all_tags,omitempty"`
+	ExcludedTags *string `json:"excluded_tags,omitempty"`
+	Order        *int    `json:"order,omitempty"`
 }
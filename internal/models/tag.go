@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 type Tag struct {
 	ID            int       `json:"id"`
@@ -19,3 +22,12 @@ type TagList struct {
 type TagCreate struct {
 	Name string `json:"name"`
 }
+
+// SameTags reports whether two lists hold the same tag names, ignoring
+// order.
+func SameTags(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,207 @@
+// Package netscape reads and writes the Netscape bookmark file format
+// (bookmarks.html) that browsers and most bookmark services export.
+package netscape
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// Bookmark is a single <A> entry of a bookmark file.
+type Bookmark struct {
+	URL          string
+	Title        string
+	Description  string
+	Tags         []string
+	Folders      []string // Enclosing folder names, outermost first
+	AddDate      time.Time
+	LastModified time.Time
+	Private      bool // True unless the entry is marked PRIVATE="0"
+	ToRead       bool
+}
+
+// Parse reads every bookmark from a Netscape bookmark file. The format is
+// loosely specified HTML (unclosed <DT> and <P> tags are the norm), so the
+// file is tokenized rather than parsed into a tree.
+func Parse(r io.Reader) ([]Bookmark, error) {
+	p := &parser{}
+	tokenizer := html.NewTokenizer(r)
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, fmt.Errorf("failed to parse bookmark file: %w", err)
+			}
+			p.endDescription()
+			return p.bookmarks, nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			p.startTag(token)
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			p.endTag(token.Data)
+
+		case html.TextToken:
+			p.text.WriteString(string(tokenizer.Text()))
+		}
+	}
+}
+
+type parser struct {
+	bookmarks []Bookmark
+
+	folders       []string
+	pendingFolder string
+	hasPending    bool
+
+	current   *Bookmark
+	inHeading bool
+	text      strings.Builder
+
+	// lastCreated is the 1-based index of the bookmark a following <DD>
+	// would describe, or 0 when a <DD> would belong to something else.
+	lastCreated int
+	inDesc      bool
+	descTarget  int
+}
+
+func (p *parser) startTag(token html.Token) {
+	switch token.Data {
+	case "dd":
+		p.endDescription()
+		// A <DD> right after a bookmark holds its description; after a
+		// folder heading it describes the folder and is ignored.
+		if p.lastCreated > 0 {
+			p.inDesc = true
+			p.descTarget = p.lastCreated - 1
+			p.text.Reset()
+		}
+
+	case "dt", "p":
+		p.endDescription()
+
+	case "h3":
+		p.endDescription()
+		p.lastCreated = 0
+		p.inHeading = true
+		p.text.Reset()
+
+	case "dl":
+		p.endDescription()
+		p.lastCreated = 0
+		// The root <DL> has no heading; keep the stack aligned with an
+		// empty name that is skipped when building folder paths.
+		name := ""
+		if p.hasPending {
+			name = p.pendingFolder
+		}
+		p.folders = append(p.folders, name)
+		p.hasPending = false
+
+	case "a":
+		p.endDescription()
+		p.current = newBookmark(token.Attr, p.folderPath())
+		p.text.Reset()
+	}
+}
+
+func (p *parser) endTag(name string) {
+	switch name {
+	case "h3":
+		if p.inHeading {
+			p.pendingFolder = strings.TrimSpace(p.text.String())
+			p.hasPending = true
+			p.inHeading = false
+		}
+
+	case "a":
+		if p.current != nil {
+			p.current.Title = strings.TrimSpace(p.text.String())
+			if p.current.URL != "" {
+				p.bookmarks = append(p.bookmarks, *p.current)
+				p.lastCreated = len(p.bookmarks)
+			}
+			p.current = nil
+		}
+
+	case "dl":
+		p.endDescription()
+		p.lastCreated = 0
+		if len(p.folders) > 0 {
+			p.folders = p.folders[:len(p.folders)-1]
+		}
+	}
+}
+
+func (p *parser) endDescription() {
+	if !p.inDesc {
+		return
+	}
+	p.bookmarks[p.descTarget].Description = strings.TrimSpace(p.text.String())
+	p.inDesc = false
+	p.lastCreated = 0
+}
+
+func (p *parser) folderPath() []string {
+	var path []string
+	for _, name := range p.folders {
+		if name != "" {
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+func newBookmark(attrs []html.Attribute, folders []string) *Bookmark {
+	b := &Bookmark{Folders: folders, Private: true}
+
+	for _, attr := range attrs {
+		value := strings.TrimSpace(attr.Val)
+		switch attr.Key {
+		case "href":
+			b.URL = value
+		case "add_date":
+			b.AddDate = parseTimestamp(value)
+		case "last_modified":
+			b.LastModified = parseTimestamp(value)
+		case "tags":
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					b.Tags = append(b.Tags, tag)
+				}
+			}
+		case "private":
+			b.Private = value != "0"
+		case "toread":
+			b.ToRead = value == "1"
+		}
+	}
+
+	return b
+}
+
+// parseTimestamp reads a Unix timestamp. Most exporters write seconds, but
+// some write milliseconds or microseconds; those are recognized by size.
+func parseTimestamp(value string) time.Time {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+
+	switch {
+	case n > 1e15:
+		return time.UnixMicro(n).UTC()
+	case n > 1e12:
+		return time.UnixMilli(n).UTC()
+	default:
+		return time.Unix(n, 0).UTC()
+	}
+}
@@ -0,0 +1,124 @@
+package netscape
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const nestedFile = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><A HREF="https://example.com/top" ADD_DATE="1700000000">Top</A>
+    <DT><H3 ADD_DATE="1700000000">Dev</H3>
+    <DD>Folder notes, not a description
+    <DL><p>
+        <DT><A HREF="https://go.dev" ADD_DATE="1700000000000" TAGS="go, lang,,">Go</A>
+        <DD>The Go
+        programming language
+        <DT><H3>Tools</H3>
+        <DL><p>
+            <DT><A HREF="https://git-scm.com" PRIVATE="0" TOREAD="1">Git</A>
+        </DL><p>
+        <DT><A HREF="https://pkg.go.dev" LAST_MODIFIED="1700000000000000">Packages</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/bottom">Bottom</A>
+</DL><p>
+`
+
+func TestParseNested(t *testing.T) {
+	got, err := Parse(strings.NewReader(nestedFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp := time.Unix(1700000000, 0).UTC()
+	want := []Bookmark{
+		{URL: "https://example.com/top", Title: "Top", AddDate: stamp, Private: true},
+		{URL: "https://go.dev", Title: "Go", Description: "The Go\n        programming language", Tags: []string{"go", "lang"}, Folders: []string{"Dev"}, AddDate: stamp, Private: true},
+		{URL: "https://git-scm.com", Title: "Git", Folders: []string{"Dev", "Tools"}, ToRead: true},
+		{URL: "https://pkg.go.dev", Title: "Packages", Folders: []string{"Dev"}, LastModified: stamp, Private: true},
+		{URL: "https://example.com/bottom", Title: "Bottom", Private: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Bookmark
+	}{
+		{
+			name:  "missing ADD_DATE",
+			input: `<DL><DT><A HREF="https://example.com">Example</A></DL>`,
+			want:  []Bookmark{{URL: "https://example.com", Title: "Example", Private: true}},
+		},
+		{
+			name:  "invalid ADD_DATE",
+			input: `<DL><DT><A HREF="https://example.com" ADD_DATE="yesterday">Example</A></DL>`,
+			want:  []Bookmark{{URL: "https://example.com", Title: "Example", Private: true}},
+		},
+		{
+			name:  "entities in titles",
+			input: `<DL><DT><A HREF="https://example.com/?a=1&amp;b=2">Tom &amp; Jerry &lt;3 &quot;cartoons&quot; &#233;t&eacute;</A><DD>Fish &gt; chips</DL>`,
+			want:  []Bookmark{{URL: "https://example.com/?a=1&b=2", Title: `Tom & Jerry <3 "cartoons" été`, Description: "Fish > chips", Private: true}},
+		},
+		{
+			name:  "unclosed DL",
+			input: `<DL><p><DT><H3>Open</H3><DL><p><DT><A HREF="https://example.com/a">A</A><DD>Last one`,
+			want:  []Bookmark{{URL: "https://example.com/a", Title: "A", Description: "Last one", Folders: []string{"Open"}, Private: true}},
+		},
+		{
+			name:  "stray closing DL",
+			input: `</DL></DL><DT><A HREF="https://example.com">Example</A>`,
+			want:  []Bookmark{{URL: "https://example.com", Title: "Example", Private: true}},
+		},
+		{
+			name:  "link without HREF",
+			input: `<DL><DT><A NAME="anchor">Nowhere</A><DD>Orphan<DT><A HREF="https://example.com">Example</A></DL>`,
+			want:  []Bookmark{{URL: "https://example.com", Title: "Example", Private: true}},
+		},
+		{
+			name:  "empty",
+			input: "",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse:\n got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Unix(1700000000, 0).UTC()
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"1700000000", want},
+		{"1700000000000", want},
+		{"1700000000000000", want},
+		{"0", time.Time{}},
+		{"-5", time.Time{}},
+		{"", time.Time{}},
+		{"soon", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseTimestamp(tt.value); !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
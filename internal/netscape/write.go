@@ -0,0 +1,96 @@
+package netscape
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+const header = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<!-- This is an automatically generated file.
+     It will be read and overwritten.
+     DO NOT EDIT! -->
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+`
+
+const footer = "</DL><p>\n"
+
+// Writer writes a Netscape bookmark file one bookmark at a time, so exports
+// don't have to hold every bookmark in memory. Folders are not written;
+// tags go into the TAGS attribute.
+type Writer struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+func (w *Writer) Write(b Bookmark) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	title := b.Title
+	if title == "" {
+		title = b.URL
+	}
+
+	var attrs strings.Builder
+	fmt.Fprintf(&attrs, ` HREF="%s"`, html.EscapeString(b.URL))
+	if !b.AddDate.IsZero() {
+		fmt.Fprintf(&attrs, ` ADD_DATE="%d"`, b.AddDate.Unix())
+	}
+	if !b.LastModified.IsZero() {
+		fmt.Fprintf(&attrs, ` LAST_MODIFIED="%d"`, b.LastModified.Unix())
+	}
+	fmt.Fprintf(&attrs, ` PRIVATE="%s"`, flag(b.Private))
+	fmt.Fprintf(&attrs, ` TOREAD="%s"`, flag(b.ToRead))
+	if len(b.Tags) > 0 {
+		fmt.Fprintf(&attrs, ` TAGS="%s"`, html.EscapeString(strings.Join(b.Tags, ",")))
+	}
+
+	if _, err := fmt.Fprintf(w.w, "    <DT><A%s>%s</A>\n", attrs.String(), html.EscapeString(title)); err != nil {
+		return err
+	}
+	if b.Description != "" {
+		if _, err := fmt.Fprintf(w.w, "    <DD>%s\n", html.EscapeString(b.Description)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close finishes the file. It must be called even when no bookmarks were
+// written, so the output is still a valid, empty bookmark file.
+func (w *Writer) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+	if _, err := w.w.WriteString(footer); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) start() error {
+	if w.started {
+		return nil
+	}
+	w.started = true
+	_, err := w.w.WriteString(header)
+	return err
+}
+
+func flag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
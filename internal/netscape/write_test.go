@@ -0,0 +1,78 @@
+package netscape
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	stamp := time.Unix(1700000000, 0).UTC()
+	bookmarks := []Bookmark{
+		{
+			URL:          "https://example.com/?a=1&b=2",
+			Title:        `Tom & Jerry <3 "cartoons"`,
+			Description:  "Fish > chips & more",
+			Tags:         []string{"tv", "c&c"},
+			AddDate:      stamp,
+			LastModified: stamp.Add(time.Hour),
+			Private:      true,
+			ToRead:       true,
+		},
+		{URL: "https://go.dev", Title: "Go"},
+		{URL: "https://été.example", Title: "Été", Description: "Accents ça va"},
+	}
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	for _, b := range bookmarks {
+		if err := w.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, bookmarks) {
+		t.Errorf("round trip:\n got %+v\nwant %+v", got, bookmarks)
+	}
+}
+
+func TestWriteUntitled(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if err := w.Write(Bookmark{URL: "https://example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Title != "https://example.com" {
+		t.Errorf("untitled bookmark parsed as %+v, want the URL as its title", got)
+	}
+}
+
+func TestWriteEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewWriter(&buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "<!DOCTYPE NETSCAPE-Bookmark-file-1>") || !strings.HasSuffix(buf.String(), footer) {
+		t.Errorf("empty file is %q", buf.String())
+	}
+	got, err := Parse(&buf)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Parse of an empty file = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,255 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/daveonkels/clinkding/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Encoder writes a stream of records in one output format. Encode is
+// called once per record as it becomes available; Close finishes the
+// output, e.g. by closing a JSON array or rendering a buffered table.
+type Encoder interface {
+	Encode(v interface{}) error
+	Close() error
+}
+
+// Columns names the fields a command shows by default in the table and
+// plain formats. CSV and TSV write every field of the record type unless
+// Delimited names some.
+type Columns struct {
+	Table     []string
+	Plain     []string
+	Delimited []string
+}
+
+// newEncoder returns the encoder for format. list selects between a
+// sequence of records and a single one where the format distinguishes
+// them (JSON and YAML).
+func newEncoder(w io.Writer, format string, columns Columns, tmpl *template.Template, list bool) Encoder {
+	switch format {
+	case config.FormatJSON:
+		if list {
+			return &jsonArrayEncoder{array: NewJSONArray(w)}
+		}
+		return &jsonEncoder{writer: w}
+	case config.FormatNDJSON:
+		return &ndjsonEncoder{encoder: json.NewEncoder(w)}
+	case config.FormatYAML:
+		return &yamlEncoder{writer: w, list: list}
+	case config.FormatCSV:
+		return &delimitedEncoder{writer: w, csv: csv.NewWriter(w), header: true, columns: columns.Delimited}
+	case config.FormatTSV:
+		return &delimitedEncoder{writer: w, header: true, columns: columns.Delimited}
+	case config.FormatPlain:
+		return &delimitedEncoder{writer: w, columns: columns.Plain}
+	case config.FormatTemplate:
+		return &templateEncoder{writer: w, tmpl: tmpl}
+	default:
+		return &tableEncoder{columns: columns.Table}
+	}
+}
+
+// record dereferences pointers so field accessors see the model value.
+func record(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return v
+	}
+	return rv.Interface()
+}
+
+type jsonEncoder struct {
+	writer io.Writer
+}
+
+func (e *jsonEncoder) Encode(v interface{}) error {
+	encoder := json.NewEncoder(e.writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func (e *jsonEncoder) Close() error { return nil }
+
+type jsonArrayEncoder struct {
+	array *JSONArray
+}
+
+func (e *jsonArrayEncoder) Encode(v interface{}) error { return e.array.Add(v) }
+func (e *jsonArrayEncoder) Close() error               { return e.array.Close() }
+
+// ndjsonEncoder writes one compact JSON object per line.
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(v interface{}) error { return e.encoder.Encode(v) }
+func (e *ndjsonEncoder) Close() error               { return nil }
+
+// yamlEncoder writes records as a YAML sequence, one item at a time, or a
+// single record as a mapping. Keys are the JSON property names.
+type yamlEncoder struct {
+	writer io.Writer
+	list   bool
+	count  int
+}
+
+func (e *yamlEncoder) Encode(v interface{}) error {
+	node, err := yamlNode(v)
+	if err != nil {
+		return err
+	}
+	if e.list {
+		node = &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{node}}
+	}
+	e.count++
+
+	encoder := yaml.NewEncoder(e.writer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func (e *yamlEncoder) Close() error {
+	if e.list && e.count == 0 {
+		_, err := io.WriteString(e.writer, "[]\n")
+		return err
+	}
+	return nil
+}
+
+// yamlNode converts v to a YAML node via its JSON encoding, so YAML output
+// uses the same property names and order as JSON.
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle drops the JSON flow style so the output is block YAML.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// delimitedEncoder writes one line per record: CSV when csv is set,
+// otherwise tab-separated with tabs and line breaks in values replaced by
+// spaces. header adds a first line with the field names.
+type delimitedEncoder struct {
+	writer  io.Writer
+	csv     *csv.Writer
+	header  bool
+	columns []string
+	fields  []Field
+}
+
+func (e *delimitedEncoder) Encode(v interface{}) error {
+	v = record(v)
+	if e.fields == nil {
+		fields, err := SelectFields(v, e.columns)
+		if err != nil {
+			return err
+		}
+		e.fields = fields
+
+		if e.header {
+			if err := e.write(fieldNames(fields)); err != nil {
+				return err
+			}
+		}
+	}
+
+	values := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		values[i] = f.Value(v)
+	}
+	return e.write(values)
+}
+
+func (e *delimitedEncoder) write(values []string) error {
+	if e.csv != nil {
+		return e.csv.Write(values)
+	}
+	for i, value := range values {
+		values[i] = sanitizeField(value)
+	}
+	_, err := fmt.Fprintln(e.writer, strings.Join(values, "\t"))
+	return err
+}
+
+func (e *delimitedEncoder) Close() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+type templateEncoder struct {
+	writer io.Writer
+	tmpl   *template.Template
+}
+
+func (e *templateEncoder) Encode(v interface{}) error { return executeTemplate(e.writer, e.tmpl, v) }
+func (e *templateEncoder) Close() error               { return nil }
+
+// tableEncoder buffers records, since column widths depend on every row,
+// and renders them on Close.
+type tableEncoder struct {
+	columns []string
+	fields  []Field
+	table   *Table
+}
+
+func (e *tableEncoder) Encode(v interface{}) error {
+	v = record(v)
+	if e.fields == nil {
+		fields, err := SelectFields(v, e.columns)
+		if err != nil {
+			return err
+		}
+		e.fields = fields
+
+		headers := make([]string, len(fields))
+		for i, f := range fields {
+			headers[i] = f.Header
+		}
+		e.table = NewTable(headers)
+	}
+
+	row := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		row[i] = f.Display(v)
+	}
+	e.table.Append(row)
+	return nil
+}
+
+func (e *tableEncoder) Close() error {
+	if e.table != nil {
+		e.table.Render()
+	}
+	return nil
+}
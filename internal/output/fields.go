@@ -0,0 +1,276 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// Field is a named column of a record type. The table, plain, CSV and TSV
+// encoders pick fields by name.
+type Field struct {
+	Name   string
+	Header string
+
+	value   func(v interface{}) string
+	display func(v interface{}) string
+}
+
+// Value returns the field's raw text, as written to plain, CSV and TSV
+// output.
+func (f Field) Value(v interface{}) string {
+	return f.value(v)
+}
+
+// Display returns the field's text for tables, which may be shortened
+// (dates without the time, for example).
+func (f Field) Display(v interface{}) string {
+	if f.display != nil {
+		return f.display(v)
+	}
+	return f.value(v)
+}
+
+// field builds a Field for records of type T.
+func field[T any](name, header string, value func(T) string) Field {
+	return Field{
+		Name:   name,
+		Header: header,
+		value:  func(v interface{}) string { return value(v.(T)) },
+	}
+}
+
+func (f Field) shown(display func(v interface{}) string) Field {
+	f.display = display
+	return f
+}
+
+// registry holds the fields of the API models, in their default order.
+var registry = map[reflect.Type][]Field{
+	reflect.TypeOf(models.Bookmark{}): {
+		field("id", "ID", func(b models.Bookmark) string { return strconv.Itoa(b.ID) }),
+		field("url", "URL", func(b models.Bookmark) string { return b.URL }),
+		field("title", "Title", func(b models.Bookmark) string { return b.Title }),
+		field("description", "Description", func(b models.Bookmark) string { return b.Description }),
+		field("notes", "Notes", func(b models.Bookmark) string { return b.Notes }),
+		field("website_title", "Website Title", func(b models.Bookmark) string { return b.WebsiteTitle }),
+		field("website_description", "Website Description", func(b models.Bookmark) string { return b.WebsiteDescription }),
+		field("archived", "Archived", func(b models.Bookmark) string { return strconv.FormatBool(b.IsArchived) }),
+		field("unread", "Unread", func(b models.Bookmark) string { return strconv.FormatBool(b.Unread) }),
+		field("shared", "Shared", func(b models.Bookmark) string { return strconv.FormatBool(b.Shared) }),
+		field("tags", "Tags", func(b models.Bookmark) string { return strings.Join(b.TagNames, ",") }).
+			shown(func(v interface{}) string { return displayTags(v.(models.Bookmark).TagNames) }),
+		field("added", "Added", func(b models.Bookmark) string { return formatTime(b.DateAdded) }).
+			shown(func(v interface{}) string { return formatDate(v.(models.Bookmark).DateAdded) }),
+		field("modified", "Modified", func(b models.Bookmark) string { return formatTime(b.DateModified) }).
+			shown(func(v interface{}) string { return formatDate(v.(models.Bookmark).DateModified) }),
+	},
+	reflect.TypeOf(models.Tag{}): {
+		field("id", "ID", func(t models.Tag) string { return strconv.Itoa(t.ID) }),
+		field("name", "Name", func(t models.Tag) string { return t.Name }),
+		field("bookmarks", "Bookmarks", func(t models.Tag) string { return strconv.Itoa(t.BookmarkCount) }),
+		field("added", "Created", func(t models.Tag) string { return formatTime(t.DateAdded) }).
+			shown(func(v interface{}) string { return formatDate(v.(models.Tag).DateAdded) }),
+	},
+	reflect.TypeOf(models.Bundle{}): {
+		field("id", "ID", func(b models.Bundle) string { return strconv.Itoa(b.ID) }),
+		field("name", "Name", func(b models.Bundle) string { return b.Name }),
+		field("description", "Description", func(b models.Bundle) string { return b.Description }),
+		field("search", "Search", func(b models.Bundle) string { return b.Search }),
+		field("any_tags", "Any Tags", func(b models.Bundle) string { return b.AnyTags }),
+		field("all_tags", "All Tags", func(b models.Bundle) string { return b.AllTags }),
+		field("excluded_tags", "Excluded Tags", func(b models.Bundle) string { return b.ExcludedTags }),
+		field("order", "Order", func(b models.Bundle) string { return strconv.Itoa(b.Order) }),
+		field("added", "Created", func(b models.Bundle) string { return formatTime(b.DateAdded) }).
+			shown(func(v interface{}) string { return formatDate(v.(models.Bundle).DateAdded) }),
+	},
+	reflect.TypeOf(models.Asset{}): {
+		field("id", "ID", func(a models.Asset) string { return strconv.Itoa(a.ID) }),
+		field("bookmark", "Bookmark", func(a models.Asset) string { return strconv.Itoa(a.BookmarkID) }),
+		field("type", "Type", func(a models.Asset) string { return a.AssetType }),
+		field("name", "Name", func(a models.Asset) string { return a.DisplayName }),
+		field("file", "File", func(a models.Asset) string { return a.File }),
+		field("size", "Size", func(a models.Asset) string { return strconv.FormatInt(a.FileSize, 10) }).
+			shown(func(v interface{}) string { return fmt.Sprintf("%d KB", v.(models.Asset).FileSize/1024) }),
+		field("status", "Status", func(a models.Asset) string { return a.Status }),
+		field("created", "Created", func(a models.Asset) string { return formatTime(a.DateCreated) }).
+			shown(func(v interface{}) string { return formatDate(v.(models.Asset).DateCreated) }),
+	},
+}
+
+// FieldsOf returns the fields of v's type. Types without registered
+// fields get one per JSON property, with nested structs flattened into
+// dotted names such as "bookmarks.created".
+func FieldsOf(v interface{}) []Field {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if fields, ok := registry[t]; ok {
+		return fields
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return []Field{{Name: "value", Header: "Value", value: formatValue}}
+	}
+	return structFields(t, nil, "")
+}
+
+// SelectFields returns the named fields of v's type, in the given order.
+func SelectFields(v interface{}, names []string) ([]Field, error) {
+	all := FieldsOf(v)
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	selected := make([]Field, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, f := range all {
+			if f.Name == name {
+				selected = append(selected, f)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, exitcode.Usagef("unknown field %q (available: %s)", name, strings.Join(fieldNames(all), ", "))
+		}
+	}
+	return selected, nil
+}
+
+func fieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// structFields derives fields from the JSON tags of a struct type. index
+// is the path of struct field indexes leading to t.
+func structFields(t reflect.Type, index []int, prefix string) []Field {
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		path := append(append([]int{}, index...), i)
+		ft := sf.Type
+
+		// Embedded structs contribute their fields at the same level
+		if sf.Anonymous && ft.Kind() == reflect.Struct && name == "" {
+			fields = append(fields, structFields(ft, path, prefix)...)
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		name = prefix + name
+
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			fields = append(fields, structFields(ft, path, name+".")...)
+			continue
+		}
+
+		f := Field{
+			Name:   name,
+			Header: fieldHeader(name),
+			value: func(v interface{}) string {
+				rv := reflect.Indirect(reflect.ValueOf(v))
+				return formatValue(rv.FieldByIndex(path).Interface())
+			},
+		}
+
+		// Tables show dates without the time, as for the API models
+		if ft == reflect.TypeOf(time.Time{}) {
+			f = f.shown(func(v interface{}) string {
+				rv := reflect.Indirect(reflect.ValueOf(v))
+				return formatDate(rv.FieldByIndex(path).Interface().(time.Time))
+			})
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// fieldHeader turns a JSON property name such as "bookmark_count" into a
+// table header such as "Bookmark count".
+func fieldHeader(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '.' })
+	for i, word := range words {
+		switch {
+		case word == "id" || word == "url":
+			words[i] = strings.ToUpper(word)
+		case i == 0:
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// formatValue renders a value as a single line of text.
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		return formatTime(val)
+	case []string:
+		return strings.Join(val, ",")
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(v)
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return ""
+		}
+		return formatValue(rv.Elem().Interface())
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func displayTags(tags []string) string {
+	if len(tags) == 0 {
+		return "-"
+	}
+	return strings.Join(tags, ", ")
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"text/template"
 
 	"github.com/daveonkels/clinkding/internal/config"
 	"github.com/fatih/color"
@@ -13,13 +14,22 @@ import (
 type Formatter struct {
 	cfg    *config.Config
 	writer io.Writer
+	tmpl   *template.Template
+	sort   *SortSpec
 }
 
 func New(cfg *config.Config) *Formatter {
-	return &Formatter{
+	f := &Formatter{
 		cfg:    cfg,
 		writer: os.Stdout,
 	}
+	if cfg.OutputTemplate != "" {
+		// Already validated by LoadTemplate when the flag was parsed
+		f.tmpl, _ = parseTemplate(cfg.OutputTemplate)
+	}
+	// Already validated by ParseSort when the flag was parsed
+	f.sort, _ = ParseSort(cfg.Sort)
+	return f
 }
 
 func (f *Formatter) Print(format string, args ...interface{}) {
@@ -42,6 +52,55 @@ func (f *Formatter) PrintJSON(data interface{}) error {
 	return encoder.Encode(data)
 }
 
+// Format returns the selected output format.
+func (f *Formatter) Format() string {
+	if f.cfg.Output == "" {
+		return config.FormatTable
+	}
+	return f.cfg.Output
+}
+
+// NewEncoder returns an encoder for a list of records in the selected
+// output format. columns chooses the fields of the table and plain
+// formats, unless --columns overrides them. With --sort, records are
+// buffered and written in order on Close.
+func (f *Formatter) NewEncoder(columns Columns) Encoder {
+	enc := newEncoder(f.writer, f.Format(), f.columns(columns), f.tmpl, true)
+	if f.sort != nil {
+		return &sortingEncoder{next: enc, spec: f.sort}
+	}
+	return enc
+}
+
+// Sort orders a slice of records by --sort, for lists printed without an
+// encoder such as the JSON page envelope.
+func (f *Formatter) Sort(items interface{}) error {
+	return sortSlice(items, f.sort)
+}
+
+func (f *Formatter) columns(columns Columns) Columns {
+	if len(f.cfg.Columns) == 0 {
+		return columns
+	}
+	return Columns{Table: f.cfg.Columns, Plain: f.cfg.Columns, Delimited: f.cfg.Columns}
+}
+
+// PrintRecord writes a single record in the selected output format.
+// Commands print their own detail view for human output, so this is meant
+// for the machine-readable formats.
+func (f *Formatter) PrintRecord(v interface{}, columns Columns) error {
+	enc := newEncoder(f.writer, f.Format(), f.columns(columns), f.tmpl, false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// JSONArray starts a streamed JSON array on the formatter's writer.
+func (f *Formatter) JSONArray() *JSONArray {
+	return NewJSONArray(f.writer)
+}
+
 func (f *Formatter) Success(format string, args ...interface{}) {
 	if f.cfg.Quiet {
 		return
@@ -88,6 +147,19 @@ func (f *Formatter) Info(format string, args ...interface{}) {
 	}
 }
 
+// DryRun reports what a command would have done under --dry-run. Like the
+// other status messages, it is meant for human output only.
+func (f *Formatter) DryRun(format string, args ...interface{}) {
+	if f.cfg.Quiet || !f.cfg.HumanOutput() {
+		return
+	}
+	prefix := "Dry run:"
+	if f.shouldUseColor() {
+		prefix = color.New(color.FgYellow).Sprint(prefix)
+	}
+	_, _ = fmt.Fprintf(f.writer, prefix+" "+format+"\n", args...)
+}
+
 func (f *Formatter) Bold(text string) string {
 	if f.shouldUseColor() {
 		return color.New(color.Bold).Sprint(text)
@@ -102,11 +174,20 @@ func (f *Formatter) Dim(text string) string {
 	return text
 }
 
+// Highlight marks a word matching a search: bold yellow on a terminal,
+// between asterisks otherwise.
+func (f *Formatter) Highlight(text string) string {
+	if f.shouldUseColor() {
+		return color.New(color.FgYellow, color.Bold).Sprint(text)
+	}
+	return "*" + text + "*"
+}
+
 func (f *Formatter) shouldUseColor() bool {
 	if f.cfg.NoColor {
 		return false
 	}
-	if f.cfg.OutputJSON || f.cfg.OutputPlain {
+	if !f.cfg.HumanOutput() {
 		return false
 	}
 	// Check if output is a terminal
@@ -2,6 +2,7 @@ package output
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 )
 
@@ -10,3 +11,42 @@ func PrintJSON(data interface{}) error {
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(data)
 }
+
+// JSONArray writes a JSON array one element at a time, so long result sets
+// can be printed while they are still being fetched.
+type JSONArray struct {
+	writer io.Writer
+	count  int
+}
+
+func NewJSONArray(w io.Writer) *JSONArray {
+	return &JSONArray{writer: w}
+}
+
+func (a *JSONArray) Add(v interface{}) error {
+	data, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n  "
+	if a.count == 0 {
+		prefix = "[\n  "
+	}
+	a.count++
+
+	if _, err := io.WriteString(a.writer, prefix); err != nil {
+		return err
+	}
+	_, err = a.writer.Write(data)
+	return err
+}
+
+func (a *JSONArray) Close() error {
+	closing := "\n]\n"
+	if a.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(a.writer, closing)
+	return err
+}
@@ -8,10 +8,22 @@ import (
 
 func PrintPlain(records [][]string) {
 	for _, record := range records {
-		fmt.Println(strings.Join(record, "\t"))
+		PrintPlainLine(record...)
 	}
 }
 
 func PrintPlainLine(fields ...string) {
-	_, _ = fmt.Fprintln(os.Stdout, strings.Join(fields, "\t"))
+	sanitized := make([]string, len(fields))
+	for i, field := range fields {
+		sanitized[i] = sanitizeField(field)
+	}
+	_, _ = fmt.Fprintln(os.Stdout, strings.Join(sanitized, "\t"))
+}
+
+// fieldReplacer keeps tab-separated output one record per line with a
+// fixed number of columns.
+var fieldReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+func sanitizeField(s string) string {
+	return fieldReplacer.Replace(s)
 }
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// progressInterval limits how often a progress bar is redrawn.
+const progressInterval = 100 * time.Millisecond
+
+// Progress draws a progress bar for a transfer on stderr, so it never mixes
+// with the output on stdout. It draws nothing unless stderr is a terminal
+// and the output is for humans. Set may be called from another goroutine.
+type Progress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	label   string
+	total   int64
+	current int64
+	drawn   time.Time
+	visible bool
+}
+
+// NewProgress starts a progress bar for label, which transfers total bytes,
+// or an unknown number if total is 0.
+func (f *Formatter) NewProgress(label string, total int64) *Progress {
+	p := &Progress{label: label, total: total}
+	if f.cfg.HumanOutput() && !f.cfg.Quiet && term.IsTerminal(int(os.Stderr.Fd())) {
+		p.w = os.Stderr
+	}
+	return p
+}
+
+// Active reports whether the bar is drawn.
+func (p *Progress) Active() bool {
+	return p.w != nil
+}
+
+// Set records that n bytes have been transferred.
+func (p *Progress) Set(n int64) {
+	if p.w == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = n
+	if (p.total <= 0 || n < p.total) && time.Since(p.drawn) < progressInterval {
+		return
+	}
+	p.draw()
+}
+
+// Update records that n of total bytes have been transferred, for
+// transfers whose total is only known once they start. A total of 0
+// leaves it unchanged.
+func (p *Progress) Update(n, total int64) {
+	if p.w == nil {
+		return
+	}
+	p.mu.Lock()
+	if total > 0 {
+		p.total = total
+	}
+	p.mu.Unlock()
+	p.Set(n)
+}
+
+// Done removes the bar, leaving the line free for the result.
+func (p *Progress) Done() {
+	if p.w == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.visible {
+		_, _ = fmt.Fprint(p.w, "\r\033[K")
+		p.visible = false
+	}
+}
+
+func (p *Progress) draw() {
+	if p.total <= 0 {
+		// Without a total there is no bar, only the count
+		_, _ = fmt.Fprintf(p.w, "\r%s %s\033[K", p.label, FormatBytes(p.current))
+		p.drawn = time.Now()
+		p.visible = true
+		return
+	}
+
+	percent := int(p.current * 100 / p.total)
+	counts := fmt.Sprintf(" %3d%% %s / %s", percent, FormatBytes(p.current), FormatBytes(p.total))
+
+	width := 80
+	if w, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && w > 0 {
+		width = w
+	}
+	label := []rune(p.label)
+	barWidth := width - len(counts) - 4 - len(label)
+	if barWidth < 10 {
+		// Shorten the label before the bar
+		keep := max(width-len(counts)-4-10, 0)
+		if len(label) > keep {
+			label = label[:keep]
+		}
+		barWidth = max(width-len(counts)-4-len(label), 0)
+	}
+	filled := barWidth * min(percent, 100) / 100
+
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
+	_, _ = fmt.Fprintf(p.w, "\r%s [%s]%s\033[K", string(label), bar, counts)
+	p.drawn = time.Now()
+	p.visible = true
+}
+
+// FormatBytes formats a size in bytes with a binary unit, such as "4.2 MB".
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "KMGTP"[exp])
+}
@@ -0,0 +1,150 @@
+package output
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+)
+
+// SortSpec orders records by one field, as given to --sort.
+type SortSpec struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses a --sort value of the form "field", "field:asc" or
+// "field:desc". An empty value means no sorting.
+func ParseSort(value string) (*SortSpec, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	name, order, _ := strings.Cut(value, ":")
+	spec := &SortSpec{Field: strings.TrimSpace(name)}
+	if spec.Field == "" {
+		return nil, exitcode.Usagef("invalid --sort %q: missing field name", value)
+	}
+	switch strings.ToLower(order) {
+	case "", "asc":
+	case "desc":
+		spec.Desc = true
+	default:
+		return nil, exitcode.Usagef("invalid --sort order %q (valid: asc, desc)", order)
+	}
+	return spec, nil
+}
+
+// sortRecords stably sorts records of one type by the spec's field.
+func sortRecords(records []interface{}, spec *SortSpec) error {
+	if spec == nil || len(records) == 0 {
+		return nil
+	}
+
+	fields, err := SelectFields(records[0], []string{spec.Field})
+	if err != nil {
+		return err
+	}
+	f := fields[0]
+
+	keys := make([]string, len(records))
+	for i, r := range records {
+		keys[i] = f.Value(r)
+	}
+	sort.Stable(recordSorter{records: records, keys: keys, desc: spec.Desc})
+	return nil
+}
+
+type recordSorter struct {
+	records []interface{}
+	keys    []string
+	desc    bool
+}
+
+func (s recordSorter) Len() int { return len(s.records) }
+
+func (s recordSorter) Less(i, j int) bool {
+	if s.desc {
+		return compareValues(s.keys[j], s.keys[i]) < 0
+	}
+	return compareValues(s.keys[i], s.keys[j]) < 0
+}
+
+func (s recordSorter) Swap(i, j int) {
+	s.records[i], s.records[j] = s.records[j], s.records[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+// compareValues orders two field values: numerically when both are
+// numbers, chronologically when both are timestamps, and otherwise as
+// case-insensitive text. Empty values sort first.
+func compareValues(a, b string) int {
+	if a == "" || b == "" {
+		return len(a) - len(b)
+	}
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, err := time.Parse(time.RFC3339, a); err == nil {
+		if y, err := time.Parse(time.RFC3339, b); err == nil {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// sortSlice sorts a slice of records in place.
+func sortSlice(slice interface{}, spec *SortSpec) error {
+	rv := reflect.ValueOf(slice)
+	if spec == nil || rv.Kind() != reflect.Slice || rv.Len() == 0 {
+		return nil
+	}
+
+	records := make([]interface{}, rv.Len())
+	for i := range records {
+		records[i] = rv.Index(i).Interface()
+	}
+	if err := sortRecords(records, spec); err != nil {
+		return err
+	}
+	for i, r := range records {
+		rv.Index(i).Set(reflect.ValueOf(r))
+	}
+	return nil
+}
+
+// sortingEncoder buffers every record and passes them to the next encoder
+// in order on Close.
+type sortingEncoder struct {
+	next    Encoder
+	spec    *SortSpec
+	records []interface{}
+}
+
+func (e *sortingEncoder) Encode(v interface{}) error {
+	e.records = append(e.records, v)
+	return nil
+}
+
+func (e *sortingEncoder) Close() error {
+	if err := sortRecords(e.records, e.spec); err != nil {
+		return err
+	}
+	for _, r := range e.records {
+		if err := e.next.Encode(r); err != nil {
+			return err
+		}
+	}
+	return e.next.Close()
+}
@@ -3,11 +3,23 @@ package output
 import (
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
 )
 
+// columnGap separates table columns.
+const columnGap = "  "
+
+// minColumnWidth is the narrowest a column is shrunk to when fitting a
+// table to the terminal, unless its header or contents are narrower.
+const minColumnWidth = 8
+
 type Table struct {
 	headers []string
 	rows    [][]string
@@ -32,15 +44,16 @@ func (t *Table) Render() {
 	// Calculate column widths
 	widths := make([]int, len(t.headers))
 	for i, h := range t.headers {
-		widths[i] = len(h)
+		widths[i] = runewidth.StringWidth(h)
 	}
 	for _, row := range t.rows {
 		for i, cell := range row {
-			if i < len(widths) && len(cell) > widths[i] {
-				widths[i] = len(cell)
+			if i < len(widths) {
+				widths[i] = max(widths[i], displayWidth(cell))
 			}
 		}
 	}
+	fitColumns(widths, t.headers, TerminalWidth())
 
 	// Check if we should use colors
 	useColor := shouldUseColors()
@@ -55,7 +68,7 @@ func (t *Table) Render() {
 			headerRow[i] = padded
 		}
 	}
-	fmt.Println(strings.Join(headerRow, "  "))
+	fmt.Println(strings.Join(headerRow, columnGap))
 
 	// Print separator with dim color
 	separators := make([]string, len(t.headers))
@@ -67,14 +80,14 @@ func (t *Table) Render() {
 			separators[i] = strings.Repeat("-", w)
 		}
 	}
-	fmt.Println(strings.Join(separators, "  "))
+	fmt.Println(strings.Join(separators, columnGap))
 
 	// Print rows with subtle coloring on first column (ID)
 	for _, row := range t.rows {
 		rowCells := make([]string, len(t.headers))
 		for i := 0; i < len(t.headers); i++ {
 			if i < len(row) {
-				padded := padRight(row[i], widths[i])
+				padded := padRight(TruncateString(row[i], widths[i]), widths[i])
 				// Color the first column (usually ID) in green
 				if i == 0 && useColor {
 					rowCells[i] = color.New(color.FgGreen).Sprint(padded)
@@ -85,10 +98,53 @@ func (t *Table) Render() {
 				rowCells[i] = padRight("", widths[i])
 			}
 		}
-		fmt.Println(strings.Join(rowCells, "  "))
+		fmt.Println(strings.Join(rowCells, columnGap))
 	}
 }
 
+// fitColumns shrinks widths so a row fits in total display columns,
+// narrowing the widest column first so short columns such as IDs and dates
+// stay intact. A total of 0 means no limit.
+func fitColumns(widths []int, headers []string, total int) {
+	if total <= 0 {
+		return
+	}
+
+	minimums := make([]int, len(widths))
+	used := len(columnGap) * (len(widths) - 1)
+	for i, w := range widths {
+		minimums[i] = min(w, max(runewidth.StringWidth(headers[i]), minColumnWidth))
+		used += w
+	}
+
+	for used > total {
+		widest := -1
+		for i, w := range widths {
+			if w > minimums[i] && (widest < 0 || w > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		widths[widest]--
+		used--
+	}
+}
+
+// TerminalWidth returns the width of the terminal on stdout, or the
+// COLUMNS environment variable when stdout isn't a terminal. It returns 0
+// when neither is known.
+func TerminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
 func shouldUseColors() bool {
 	// Check NO_COLOR environment variable
 	if os.Getenv("NO_COLOR") != "" {
@@ -99,21 +155,70 @@ func shouldUseColors() bool {
 	return (fileInfo.Mode() & os.ModeCharDevice) != 0
 }
 
-func padRight(s string, width int) string {
-	if len(s) >= width {
-		return s
+// ansiSequence matches the color escapes a cell may contain, such as
+// highlighted search matches. They take up no columns.
+var ansiSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// displayWidth returns the number of columns s takes up on a terminal.
+func displayWidth(s string) int {
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		s = ansiSequence.ReplaceAllString(s, "")
 	}
-	return s + strings.Repeat(" ", width-len(s))
+	return runewidth.StringWidth(s)
 }
 
+// padRight pads s with spaces to width display columns.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-displayWidth(s), 0))
+}
+
+// TruncateString shortens s to at most maxLen display columns, ending it
+// with "..." when it was cut. Wide characters such as CJK text and emoji
+// count as two columns.
 func TruncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if displayWidth(s) <= maxLen {
 		return s
 	}
+	if strings.IndexByte(s, '\x1b') >= 0 {
+		return truncateColored(s, maxLen)
+	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return runewidth.Truncate(s, maxLen, "")
+	}
+	return runewidth.Truncate(s, maxLen, "...")
+}
+
+// truncateColored truncates s like TruncateString, keeping its color
+// escapes out of the count and resetting colors after the cut.
+func truncateColored(s string, maxLen int) string {
+	tail := "..."
+	if maxLen <= 3 {
+		tail = ""
+	}
+	limit := maxLen - len(tail)
+
+	var b strings.Builder
+	width := 0
+	pending := ""
+	for s != "" {
+		// Escapes are only kept if text follows them
+		if loc := ansiSequence.FindStringIndex(s); loc != nil && loc[0] == 0 {
+			pending += s[:loc[1]]
+			s = s[loc[1]:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s)
+		w := runewidth.RuneWidth(r)
+		if width+w > limit {
+			break
+		}
+		b.WriteString(pending)
+		pending = ""
+		b.WriteRune(r)
+		width += w
+		s = s[size:]
 	}
-	return s[:maxLen-3] + "..."
+	return b.String() + "\x1b[0m" + tail
 }
 
 func FormatTags(tags []string, maxLen int) string {
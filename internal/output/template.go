@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/daveonkels/clinkding/internal/exitcode"
+)
+
+// templateFuncs are available to --format templates. Functions taking an
+// option put it first, so they read naturally in pipelines:
+//
+//	{{.TagNames | join ","}}  {{.Title | truncate 30}}  {{.DateAdded | date "2006-01-02"}}
+var templateFuncs = template.FuncMap{
+	"join": func(sep string, items []string) string {
+		return strings.Join(items, sep)
+	},
+	"truncate": func(n int, s string) string {
+		runes := []rune(s)
+		if len(runes) <= n {
+			return s
+		}
+		if n <= 3 {
+			return string(runes[:n])
+		}
+		return string(runes[:n-3]) + "..."
+	},
+	"date": func(layout string, t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Local().Format(layout)
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// LoadTemplate resolves a --format value, reading it from a file when it
+// starts with "@", and checks that it parses. It returns the template text.
+func LoadTemplate(spec string) (string, error) {
+	text := spec
+	if path, ok := strings.CutPrefix(spec, "@"); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	}
+
+	if _, err := parseTemplate(text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate renders data, ending the output with a newline unless the
+// template already does, so each item gets its own line.
+func executeTemplate(w io.Writer, tmpl *template.Template, data interface{}) error {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return exitcode.Wrap(exitcode.Usage, fmt.Errorf("failed to render --format template: %w", err))
+	}
+
+	out := buf.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
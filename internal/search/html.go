@@ -0,0 +1,53 @@
+package search
+
+import (
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxContent caps the snapshot text indexed for one bookmark, so a huge
+// page doesn't swamp the index.
+const maxContent = 256 << 10
+
+// HTMLText extracts the readable text of an HTML page, leaving out
+// scripts, styles and other markup that isn't shown.
+func HTMLText(r io.Reader) (string, error) {
+	z := html.NewTokenizer(r)
+	var b strings.Builder
+	skip := 0
+	for b.Len() < maxContent {
+		switch z.Next() {
+		case html.ErrorToken:
+			if err := z.Err(); err != io.EOF {
+				return "", err
+			}
+			return strings.Join(strings.Fields(b.String()), " "), nil
+		case html.StartTagToken:
+			if hidden(z) {
+				skip++
+			}
+		case html.EndTagToken:
+			if hidden(z) && skip > 0 {
+				skip--
+			}
+		case html.TextToken:
+			if skip == 0 {
+				b.Write(z.Text())
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// hidden reports whether the current tag's contents aren't shown as text.
+func hidden(z *html.Tokenizer) bool {
+	name, _ := z.TagName()
+	switch string(name) {
+	case "script", "style", "noscript", "template", "svg", "head":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+// Package search keeps a local full-text index of bookmarks, with ranked,
+// field-weighted, fuzzy matching that linkding's own search lacks.
+package search
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// StaleAfter is how old an index may be before searches suggest rebuilding
+// it.
+const StaleAfter = 24 * time.Hour
+
+// formatVersion changes whenever the saved index layout does, so an index
+// written by another version is rebuilt rather than misread.
+const formatVersion = 1
+
+// field is a part of a bookmark that is indexed on its own.
+type field uint8
+
+const (
+	fieldTitle field = iota
+	fieldTags
+	fieldDescription
+	fieldNotes
+	fieldURL
+	fieldContent
+	numFields
+)
+
+var fieldNames = [numFields]string{"title", "tags", "description", "notes", "url", "content"}
+
+// fieldWeights make a match in the title count for more than one in the
+// URL or the snapshot text.
+var fieldWeights = [numFields]float64{3, 2.5, 1.5, 1, 1, 0.5}
+
+// Document is a bookmark to index, with the text of its HTML snapshot if
+// one was fetched.
+type Document struct {
+	Bookmark models.Bookmark
+	// Content is the text of the snapshot asset SnapshotID.
+	Content    string
+	SnapshotID int
+	// Lengths is the number of words in each field, set by Build.
+	Lengths [numFields]int
+}
+
+// text returns the contents of a field. The page's own title and
+// description stand in for empty ones, as linkding displays them.
+func (d *Document) text(f field) string {
+	b := &d.Bookmark
+	switch f {
+	case fieldTitle:
+		if b.Title != "" {
+			return b.Title
+		}
+		return b.WebsiteTitle
+	case fieldTags:
+		return strings.Join(b.TagNames, " ")
+	case fieldDescription:
+		if b.Description != "" {
+			return b.Description
+		}
+		return b.WebsiteDescription
+	case fieldNotes:
+		return b.Notes
+	case fieldURL:
+		return b.URL
+	case fieldContent:
+		return d.Content
+	}
+	return ""
+}
+
+// Posting lists where a term occurs in one field of one document.
+type Posting struct {
+	Doc       int
+	Field     field
+	Positions []int
+}
+
+// Index is an inverted index of bookmarks: for every term, the fields it
+// occurs in and at which word positions, so phrases can be matched.
+type Index struct {
+	Version int
+	// URL is the linkding instance the bookmarks came from.
+	URL     string
+	BuiltAt time.Time
+	// Assets records whether snapshot text was fetched for the index.
+	Assets bool
+	Docs   []Document
+	Terms  map[string][]Posting
+	// TotalLengths sums the field lengths of all documents, for the
+	// average lengths ranking is relative to.
+	TotalLengths [numFields]int
+}
+
+// Build indexes docs from the linkding instance at url.
+func Build(url string, docs []Document, assets bool) *Index {
+	idx := &Index{
+		Version: formatVersion,
+		URL:     url,
+		BuiltAt: time.Now(),
+		Assets:  assets,
+		Docs:    docs,
+		Terms:   make(map[string][]Posting),
+	}
+	for i := range idx.Docs {
+		doc := &idx.Docs[i]
+		for f := field(0); f < numFields; f++ {
+			tokens := tokenize(doc.text(f))
+			doc.Lengths[f] = len(tokens)
+			idx.TotalLengths[f] += len(tokens)
+
+			positions := make(map[string][]int)
+			var order []string
+			for pos, t := range tokens {
+				if _, ok := positions[t.text]; !ok {
+					order = append(order, t.text)
+				}
+				positions[t.text] = append(positions[t.text], pos)
+			}
+			for _, term := range order {
+				idx.Terms[term] = append(idx.Terms[term], Posting{Doc: i, Field: f, Positions: positions[term]})
+			}
+		}
+	}
+	return idx
+}
+
+// SnapshotText returns the snapshot text indexed for each bookmark, by
+// snapshot asset ID, so a rebuild needn't download unchanged snapshots
+// again.
+func (idx *Index) SnapshotText() map[int]string {
+	texts := make(map[int]string)
+	for _, doc := range idx.Docs {
+		if doc.SnapshotID != 0 {
+			texts[doc.SnapshotID] = doc.Content
+		}
+	}
+	return texts
+}
+
+// Path returns the location of the index for a config profile, in the
+// user's cache directory.
+func Path(profile string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+	if profile == "" {
+		profile = "default"
+	}
+	return filepath.Join(dir, "clinkding", "search-"+profile+".gob"), nil
+}
+
+// Load reads the index saved at path. It returns nil without an error when
+// there is none, or it was saved in another format and must be rebuilt.
+func Load(path string) (*Index, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var idx Index
+	if err := gob.NewDecoder(file).Decode(&idx); err != nil || idx.Version != formatVersion {
+		return nil, nil
+	}
+	if idx.Terms == nil {
+		idx.Terms = make(map[string][]Posting)
+	}
+	return &idx, nil
+}
+
+// Save writes the index to path, replacing any earlier one only once it is
+// completely written.
+func (idx *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".search-*")
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	err = gob.NewEncoder(tmp).Encode(idx)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
+
+// token is a word of a field, with its byte offsets for highlighting.
+type token struct {
+	text       string
+	start, end int
+}
+
+// tokenize splits s into lowercase words of letters and digits.
+func tokenize(s string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{text: strings.ToLower(s[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: strings.ToLower(s[start:]), start: start, end: len(s)})
+	}
+	return tokens
+}
@@ -0,0 +1,340 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/daveonkels/clinkding/internal/fuzzy"
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+// BM25 parameters: how quickly repeated words stop adding to a score, and
+// how much longer fields are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Scores of approximate matches relative to an exact one.
+const (
+	prefixFactor = 0.8
+	fuzzyFactor1 = 0.6 // one edit away
+	fuzzyFactor2 = 0.4 // two edits away
+)
+
+// Options tune a search.
+type Options struct {
+	// Limit caps the number of results; 0 means no limit.
+	Limit int
+	// Exact turns off matching words with typos.
+	Exact bool
+	// Filter, if set, leaves out the bookmarks it returns false for.
+	Filter func(models.Bookmark) bool
+}
+
+// Result is a bookmark matching a search.
+type Result struct {
+	Bookmark models.Bookmark
+	Score    float64
+	// Fields names the fields that matched, in index order.
+	Fields []string
+	// Title and Snippet are the bookmark's title and an excerpt of the
+	// text that matched best, with the matching words marked.
+	Title   Snippet
+	Snippet Snippet
+}
+
+// clause is one part of a query, which every result must match: a word,
+// or the words of a quoted phrase in order. With prefix, the last word
+// also matches longer words starting with it.
+type clause struct {
+	terms  []string
+	prefix bool
+}
+
+// parseQuery splits a query into clauses. Quoted text is a phrase, a word
+// ending in "*" a prefix, and words joined by punctuation ("e-mail") a
+// phrase of their parts.
+func parseQuery(q string) []clause {
+	var clauses []clause
+	add := func(text string, phrase bool) {
+		text = strings.TrimSpace(text)
+		prefix := !phrase && strings.HasSuffix(text, "*")
+		var terms []string
+		for _, t := range tokenize(text) {
+			terms = append(terms, t.text)
+		}
+		if len(terms) > 0 {
+			clauses = append(clauses, clause{terms: terms, prefix: prefix})
+		}
+	}
+
+	for q != "" {
+		if start := strings.IndexByte(q, '"'); start == 0 {
+			end := strings.IndexByte(q[1:], '"')
+			if end < 0 {
+				add(q[1:], true)
+				break
+			}
+			add(q[1:end+1], true)
+			q = q[end+2:]
+			continue
+		} else if start > 0 {
+			for _, word := range strings.Fields(q[:start]) {
+				add(word, false)
+			}
+			q = q[start:]
+			continue
+		}
+		for _, word := range strings.Fields(q) {
+			add(word, false)
+		}
+		break
+	}
+	return clauses
+}
+
+// match is how one document matched a clause.
+type match struct {
+	score  float64
+	fields [numFields]bool
+	// terms are the index terms that matched, for highlighting.
+	terms map[string]bool
+}
+
+// Search returns the documents matching every clause of query, best first.
+func (idx *Index) Search(query string, opts Options) []Result {
+	clauses := parseQuery(query)
+	if len(clauses) == 0 || len(idx.Docs) == 0 {
+		return []Result{}
+	}
+
+	var matches map[int]*match
+	for _, c := range clauses {
+		found := idx.matchClause(c, opts)
+		if matches == nil {
+			matches = found
+			continue
+		}
+		for doc, m := range matches {
+			other, ok := found[doc]
+			if !ok {
+				delete(matches, doc)
+				continue
+			}
+			m.score += other.score
+			for f := range m.fields {
+				m.fields[f] = m.fields[f] || other.fields[f]
+			}
+			for term := range other.terms {
+				m.terms[term] = true
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(matches))
+	for doc, m := range matches {
+		d := &idx.Docs[doc]
+		if opts.Filter != nil && !opts.Filter(d.Bookmark) {
+			continue
+		}
+		r := Result{Bookmark: d.Bookmark, Score: m.score}
+		for f := field(0); f < numFields; f++ {
+			if m.fields[f] {
+				r.Fields = append(r.Fields, fieldNames[f])
+			}
+		}
+		r.Title = highlight(d.text(fieldTitle), m.terms)
+		if r.Title.Text == "" {
+			r.Title = highlight(d.Bookmark.URL, m.terms)
+		}
+		r.Snippet = snippet(d, m)
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Bookmark.ID > results[j].Bookmark.ID
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results
+}
+
+// variant is an index term standing in for a query word, scored by how
+// closely it matches.
+type variant struct {
+	term   string
+	factor float64
+}
+
+// variants returns the index terms a query word matches: itself, longer
+// words it starts when prefix is set, and otherwise words with a typo or
+// two unless opts.Exact is set.
+func (idx *Index) variants(word string, prefix bool, opts Options) []variant {
+	var found []variant
+	if _, ok := idx.Terms[word]; ok {
+		found = append(found, variant{word, 1})
+	}
+	switch {
+	case prefix:
+		for term := range idx.Terms {
+			if term != word && strings.HasPrefix(term, word) {
+				found = append(found, variant{term, prefixFactor})
+			}
+		}
+	case !opts.Exact:
+		maxEdits := 0
+		switch n := utf8.RuneCountInString(word); {
+		case n >= 8:
+			maxEdits = 2
+		case n >= 4:
+			maxEdits = 1
+		}
+		if maxEdits == 0 {
+			break
+		}
+		for term := range idx.Terms {
+			if term == word {
+				continue
+			}
+			if d := fuzzy.Distance(word, term, maxEdits); d == 1 {
+				found = append(found, variant{term, fuzzyFactor1})
+			} else if d == 2 && maxEdits == 2 {
+				found = append(found, variant{term, fuzzyFactor2})
+			}
+		}
+	}
+	return found
+}
+
+func (idx *Index) matchClause(c clause, opts Options) map[int]*match {
+	if len(c.terms) == 1 {
+		return idx.matchWord(c.terms[0], c.prefix, opts)
+	}
+	return idx.matchPhrase(c)
+}
+
+// matchWord scores the documents containing a word or its variants.
+func (idx *Index) matchWord(word string, prefix bool, opts Options) map[int]*match {
+	found := make(map[int]*match)
+	for _, v := range idx.variants(word, prefix, opts) {
+		postings := idx.Terms[v.term]
+		idf := idx.idf(postings)
+		for _, p := range postings {
+			m := found[p.Doc]
+			if m == nil {
+				m = &match{terms: make(map[string]bool)}
+				found[p.Doc] = m
+			}
+			m.score += v.factor * idx.fieldScore(p.Doc, p.Field, len(p.Positions), idf)
+			m.fields[p.Field] = true
+			m.terms[v.term] = true
+		}
+	}
+	return found
+}
+
+// matchPhrase scores the documents containing a phrase's words next to
+// each other in one field. Phrases aren't matched with typos.
+func (idx *Index) matchPhrase(c clause) map[int]*match {
+	type location struct {
+		doc   int
+		field field
+	}
+	// positions[i] holds where the phrase's i-th word occurs
+	positions := make([]map[location]map[int]bool, len(c.terms))
+	idf := 0.0
+	for i, word := range c.terms {
+		terms := []string{word}
+		if c.prefix && i == len(c.terms)-1 {
+			terms = nil
+			for _, v := range idx.variants(word, true, Options{Exact: true}) {
+				terms = append(terms, v.term)
+			}
+		}
+		positions[i] = make(map[location]map[int]bool)
+		for j, term := range terms {
+			postings := idx.Terms[term]
+			if j == 0 {
+				idf += idx.idf(postings)
+			}
+			for _, p := range postings {
+				loc := location{p.Doc, p.Field}
+				if positions[i][loc] == nil {
+					positions[i][loc] = make(map[int]bool)
+				}
+				for _, pos := range p.Positions {
+					positions[i][loc][pos] = true
+				}
+			}
+		}
+	}
+
+	found := make(map[int]*match)
+	for loc, starts := range positions[0] {
+		count := 0
+		for start := range starts {
+			next := true
+			for i := 1; i < len(c.terms) && next; i++ {
+				next = positions[i][loc][start+i]
+			}
+			if next {
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		m := found[loc.doc]
+		if m == nil {
+			m = &match{terms: make(map[string]bool)}
+			found[loc.doc] = m
+		}
+		m.score += idx.fieldScore(loc.doc, loc.field, count, idf)
+		m.fields[loc.field] = true
+	}
+	// Highlight the words of the phrase wherever they occur in a match
+	for _, m := range found {
+		for i, word := range c.terms {
+			if !(c.prefix && i == len(c.terms)-1) {
+				m.terms[word] = true
+				continue
+			}
+			for _, v := range idx.variants(word, true, Options{Exact: true}) {
+				m.terms[v.term] = true
+			}
+		}
+	}
+	return found
+}
+
+// idf weighs a term by how rare it is among the documents.
+func (idx *Index) idf(postings []Posting) float64 {
+	docs := 0
+	last := -1
+	for _, p := range postings {
+		if p.Doc != last {
+			docs++
+			last = p.Doc
+		}
+	}
+	n := float64(len(idx.Docs))
+	return math.Log(1 + (n-float64(docs)+0.5)/(float64(docs)+0.5))
+}
+
+// fieldScore is the BM25 score of a term occurring count times in a field,
+// weighted by the field.
+func (idx *Index) fieldScore(doc int, f field, count int, idf float64) float64 {
+	avg := float64(idx.TotalLengths[f]) / float64(len(idx.Docs))
+	if avg == 0 {
+		avg = 1
+	}
+	length := float64(idx.Docs[doc].Lengths[f])
+	tf := float64(count)
+	return fieldWeights[f] * idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*length/avg))
+}
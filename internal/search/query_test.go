@@ -0,0 +1,126 @@
+package search
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/daveonkels/clinkding/internal/models"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []clause
+	}{
+		{"golang testing", []clause{{terms: []string{"golang"}}, {terms: []string{"testing"}}}},
+		{"  Golang  ", []clause{{terms: []string{"golang"}}}},
+		{`"error handling" golang`, []clause{{terms: []string{"error", "handling"}}, {terms: []string{"golang"}}}},
+		{`golang "error handling"`, []clause{{terms: []string{"golang"}}, {terms: []string{"error", "handling"}}}},
+		{`"error handling`, []clause{{terms: []string{"error", "handling"}}}},
+		{`go "unterminated phrase here`, []clause{{terms: []string{"go"}}, {terms: []string{"unterminated", "phrase", "here"}}}},
+		{`""`, nil},
+		{`"  "`, nil},
+		{"kube*", []clause{{terms: []string{"kube"}, prefix: true}}},
+		{"kube* ctl", []clause{{terms: []string{"kube"}, prefix: true}, {terms: []string{"ctl"}}}},
+		{`"kube*"`, []clause{{terms: []string{"kube"}}}},
+		{"*", nil},
+		{"e-mail", []clause{{terms: []string{"e", "mail"}}}},
+		{"node.js*", []clause{{terms: []string{"node", "js"}, prefix: true}}},
+		{"café", []clause{{terms: []string{"café"}}}},
+	}
+	for _, tt := range tests {
+		if got := parseQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func testIndex() *Index {
+	return Build("https://links.example.com", []Document{
+		{Bookmark: models.Bookmark{ID: 1, URL: "https://a.example", Title: "Error handling in Go"}},
+		{Bookmark: models.Bookmark{ID: 2, URL: "https://b.example", Title: "Handling an error"}},
+		{Bookmark: models.Bookmark{ID: 3, URL: "https://c.example", Title: "Error pages", Notes: "Handling of them"}},
+		{Bookmark: models.Bookmark{ID: 4, URL: "https://d.example", Title: "Kubernetes operators", TagNames: []string{"k8s"}}},
+		{Bookmark: models.Bookmark{ID: 5, URL: "https://e.example", Title: "Kubectl cheat sheet", Description: "Error handling and handling errors"}},
+		{Bookmark: models.Bookmark{ID: 6, URL: "https://f.example", Title: "Go", IsArchived: true}},
+	}, false)
+}
+
+func resultIDs(results []Result) []int {
+	ids := []int{}
+	for _, r := range results {
+		ids = append(ids, r.Bookmark.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	tests := []struct {
+		query string
+		opts  Options
+		want  []int // sorted by ID, since scores are tested elsewhere
+	}{
+		// Words match anywhere, but a phrase only adjacent and in one field
+		{"error handling", Options{}, []int{1, 2, 3, 5}},
+		{`"error handling"`, Options{}, []int{1, 5}},
+		{`"handling error"`, Options{}, []int{}},
+		{`"handling errors"`, Options{}, []int{5}},
+		{`"error handling" go`, Options{}, []int{1}},
+		{"error-hand*", Options{}, []int{1, 5}},
+		// Prefixes and typos
+		{"kube*", Options{}, []int{4, 5}},
+		{"kube", Options{}, []int{}},
+		{"kubernets", Options{}, []int{4}},
+		{"kubernets", Options{Exact: true}, []int{}},
+		{"kubectll", Options{}, []int{5}},
+		{"og", Options{}, []int{}}, // too short for typos
+		{"go", Options{Filter: func(b models.Bookmark) bool { return !b.IsArchived }}, []int{1}},
+		{"go", Options{}, []int{1, 6}},
+		{"error", Options{Limit: 2}, nil},
+		{`""`, Options{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := idx.Search(tt.query, tt.opts)
+			if tt.opts.Limit > 0 {
+				if len(results) != tt.opts.Limit {
+					t.Errorf("got %d results, want %d", len(results), tt.opts.Limit)
+				}
+				return
+			}
+			got := resultIDs(results)
+			sort.Ints(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := testIndex()
+	// A match in the notes counts for less than one in the title
+	if got := resultIDs(idx.Search("handling", Options{})); len(got) != 4 || got[3] != 3 {
+		t.Errorf("handling ranked %v, want the note match last", got)
+	}
+
+	results := idx.Search(`"error handling"`, Options{})
+	for _, r := range results {
+		if r.Bookmark.ID != 5 {
+			continue
+		}
+		if !reflect.DeepEqual(r.Fields, []string{"description"}) {
+			t.Errorf("matched fields %v, want [description]", r.Fields)
+		}
+		var marked []string
+		for _, h := range r.Snippet.Highlights {
+			marked = append(marked, r.Snippet.Text[h[0]:h[1]])
+		}
+		want := []string{"Error", "handling", "handling"}
+		if !reflect.DeepEqual(marked, want) {
+			t.Errorf("snippet %q marks %q, want %q", r.Snippet.Text, marked, want)
+		}
+	}
+}
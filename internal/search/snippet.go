@@ -0,0 +1,115 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Snippet lengths in bytes: the whole excerpt, and how much of it comes
+// before the first match.
+const (
+	snippetLength  = 160
+	snippetContext = 40
+)
+
+// Snippet is a piece of text with the words that matched a search marked.
+type Snippet struct {
+	Text string
+	// Highlights are the byte ranges of the matched words in Text.
+	Highlights [][2]int
+}
+
+// Mark returns the text with each matched word passed through mark, such
+// as a function making it bold.
+func (s Snippet) Mark(mark func(string) string) string {
+	if len(s.Highlights) == 0 {
+		return s.Text
+	}
+	var b strings.Builder
+	last := 0
+	for _, h := range s.Highlights {
+		b.WriteString(s.Text[last:h[0]])
+		b.WriteString(mark(s.Text[h[0]:h[1]]))
+		last = h[1]
+	}
+	b.WriteString(s.Text[last:])
+	return b.String()
+}
+
+// highlight marks the words of text among terms, collapsing whitespace so
+// the text fits on one line.
+func highlight(text string, terms map[string]bool) Snippet {
+	s := Snippet{Text: strings.Join(strings.Fields(text), " ")}
+	for _, t := range tokenize(s.Text) {
+		if terms[t.text] {
+			s.Highlights = append(s.Highlights, [2]int{t.start, t.end})
+		}
+	}
+	return s
+}
+
+// snippet excerpts the text of a match: the description, notes or
+// snapshot text, whichever has the most matched words, around the first
+// of them. Without matches in any, it is the start of the description.
+func snippet(d *Document, m *match) Snippet {
+	best, bestCount := highlight(d.text(fieldDescription), m.terms), -1
+	for _, f := range []field{fieldDescription, fieldNotes, fieldContent} {
+		if !m.fields[f] {
+			continue
+		}
+		s := highlight(d.text(f), m.terms)
+		if len(s.Highlights) > bestCount {
+			best, bestCount = s, len(s.Highlights)
+		}
+	}
+	return excerpt(best)
+}
+
+// excerpt cuts s down to snippetLength bytes around its first highlight,
+// on word boundaries where there are any and always on character
+// boundaries, with "..." where text was left out.
+func excerpt(s Snippet) Snippet {
+	if len(s.Text) <= snippetLength {
+		return s
+	}
+
+	start := 0
+	if len(s.Highlights) > 0 {
+		start = max(s.Highlights[0][0]-snippetContext, 0)
+	}
+	end := min(start+snippetLength, len(s.Text))
+	// Snap both ends to whole words
+	if start > 0 && s.Text[start-1] != ' ' {
+		if i := strings.IndexByte(s.Text[start:], ' '); i >= 0 && start+i+1 <= s.Highlights[0][0] {
+			start += i + 1
+		}
+	}
+	if end < len(s.Text) {
+		if i := strings.LastIndexByte(s.Text[start:end], ' '); i > 0 {
+			end = start + i
+		}
+	}
+	// Without spaces to snap to, at least never split a character
+	for start < end && !utf8.RuneStart(s.Text[start]) {
+		start++
+	}
+	for end > start && end < len(s.Text) && !utf8.RuneStart(s.Text[end]) {
+		end--
+	}
+
+	out := Snippet{Text: s.Text[start:end]}
+	offset := -start
+	if start > 0 {
+		out.Text = "..." + out.Text
+		offset += 3
+	}
+	if end < len(s.Text) {
+		out.Text += "..."
+	}
+	for _, h := range s.Highlights {
+		if h[0] >= start && h[1] <= end {
+			out.Highlights = append(out.Highlights, [2]int{h[0] + offset, h[1] + offset})
+		}
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// marked returns the highlighted parts of s.
+func marked(s Snippet) []string {
+	var words []string
+	for _, h := range s.Highlights {
+		words = append(words, s.Text[h[0]:h[1]])
+	}
+	return words
+}
+
+func TestHighlight(t *testing.T) {
+	s := highlight("  Error\thandling,\n\nand ERRORS  ", map[string]bool{"error": true, "handling": true})
+	if s.Text != "Error handling, and ERRORS" {
+		t.Errorf("text = %q, want whitespace collapsed", s.Text)
+	}
+	want := [][2]int{{0, 5}, {6, 14}}
+	if !reflect.DeepEqual(s.Highlights, want) {
+		t.Errorf("highlights = %v, want %v", s.Highlights, want)
+	}
+	if got := s.Mark(strings.ToUpper); got != "ERROR HANDLING, and ERRORS" {
+		t.Errorf("Mark = %q", got)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	words := strings.Repeat("lorem ", 30)
+	tests := []struct {
+		name         string
+		text         string
+		terms        map[string]bool
+		wantMarked   []string
+		prefix, tail bool // whether "..." is expected at either end
+	}{
+		{
+			name:       "short text is kept whole",
+			text:       "find the needle here",
+			terms:      map[string]bool{"needle": true},
+			wantMarked: []string{"needle"},
+		},
+		{
+			name:       "match near the start",
+			text:       "needle " + words + words,
+			terms:      map[string]bool{"needle": true},
+			wantMarked: []string{"needle"},
+			tail:       true,
+		},
+		{
+			name:       "match in the middle",
+			text:       words + "needle " + words,
+			terms:      map[string]bool{"needle": true},
+			wantMarked: []string{"needle"},
+			prefix:     true,
+			tail:       true,
+		},
+		{
+			name:       "match at the end",
+			text:       words + words + "needle",
+			terms:      map[string]bool{"needle": true},
+			wantMarked: []string{"needle"},
+			prefix:     true,
+		},
+		{
+			name:       "matches past the end are dropped",
+			text:       "needle " + words + "needle",
+			terms:      map[string]bool{"needle": true},
+			wantMarked: []string{"needle"},
+			tail:       true,
+		},
+		{
+			name:  "no match",
+			text:  words + words,
+			terms: map[string]bool{},
+			tail:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := excerpt(highlight(tt.text, tt.terms))
+			if got := marked(s); !reflect.DeepEqual(got, tt.wantMarked) {
+				t.Errorf("excerpt %q marks %q, want %q", s.Text, got, tt.wantMarked)
+			}
+			if got := strings.HasPrefix(s.Text, "..."); got != tt.prefix {
+				t.Errorf("excerpt %q: leading ... = %v, want %v", s.Text, got, tt.prefix)
+			}
+			if got := strings.HasSuffix(s.Text, "..."); got != tt.tail {
+				t.Errorf("excerpt %q: trailing ... = %v, want %v", s.Text, got, tt.tail)
+			}
+			text := strings.TrimSuffix(strings.TrimPrefix(s.Text, "..."), "...")
+			if len(text) > snippetLength || strings.HasPrefix(text, " ") || strings.HasSuffix(text, " ") {
+				t.Errorf("excerpt %q isn't cut to whole words within %d bytes", s.Text, snippetLength)
+			}
+		})
+	}
+}
+
+func TestExcerptOffsets(t *testing.T) {
+	text := strings.Repeat("lorem ", 20) + "needle ipsum needle " + strings.Repeat("dolor ", 40)
+	start := strings.Index(text, "needle")
+	s := excerpt(highlight(text, map[string]bool{"needle": true}))
+
+	// The excerpt starts on the word after start-snippetContext, shifted
+	// right by the "..." in front
+	cut := start - snippetContext
+	cut += strings.IndexByte(text[cut:], ' ') + 1
+	want := [][2]int{
+		{start - cut + 3, start - cut + 9},
+		{start - cut + 16, start - cut + 22},
+	}
+	if !reflect.DeepEqual(s.Highlights, want) {
+		t.Errorf("highlights = %v, want %v", s.Highlights, want)
+	}
+}
+
+func TestExcerptMultibyte(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"no spaces", strings.Repeat("日", 100) + "needle" + strings.Repeat("本", 100)},
+		{"accents", strings.Repeat("é", 101) + " needle " + strings.Repeat("ü", 101)},
+		{"mixed widths", strings.Repeat("aé日", 40) + "needle" + strings.Repeat("ü日b", 40)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := strings.Index(tt.text, "needle")
+			s := excerpt(Snippet{Text: tt.text, Highlights: [][2]int{{start, start + len("needle")}}})
+			if !utf8.ValidString(s.Text) {
+				t.Errorf("excerpt %q isn't valid UTF-8", s.Text)
+			}
+			if got := marked(s); !reflect.DeepEqual(got, []string{"needle"}) {
+				t.Errorf("excerpt %q marks %q, want [needle]", s.Text, got)
+			}
+		})
+	}
+}
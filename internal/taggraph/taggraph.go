@@ -0,0 +1,156 @@
+// Package taggraph writes a tag co-occurrence graph, where tags are nodes
+// and an edge joins two tags used on the same bookmarks, as Graphviz DOT,
+// GraphML or JSON.
+package taggraph
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{"dot", "graphml", "json"}
+
+// Node is a tag and the number of bookmarks carrying it.
+type Node struct {
+	Name      string `json:"id"`
+	Bookmarks int    `json:"bookmarks"`
+}
+
+// Edge joins two tags; Weight is the number of bookmarks carrying both.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+// Graph is an undirected co-occurrence graph. Every edge's tags must be
+// among the nodes.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Write writes g to w in format, one of Formats.
+func Write(w io.Writer, format string, g *Graph) error {
+	switch format {
+	case "dot":
+		return WriteDOT(w, g)
+	case "graphml":
+		return WriteGraphML(w, g)
+	case "json":
+		return WriteJSON(w, g)
+	}
+	return fmt.Errorf("unsupported graph format %q (supported: %s)", format, strings.Join(Formats, ", "))
+}
+
+// WriteDOT writes g as a Graphviz graph. Nodes are labeled with their
+// bookmark count, and edges carry their weight for layout engines.
+func WriteDOT(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "graph tags {")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(bw, "  %s [label=%s];\n", dotID(n.Name), dotID(fmt.Sprintf("%s (%d)", n.Name, n.Bookmarks)))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(bw, "  %s -- %s [weight=%d, label=\"%d\"];\n", dotID(e.Source), dotID(e.Target), e.Weight, e.Weight)
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// dotID quotes s as a DOT identifier.
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes g as GraphML, which Gephi, Cytoscape and yEd read.
+// Node IDs are generated; the tag name is the "name" attribute.
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+			{ID: "bookmarks", For: "node", AttrName: "bookmarks", AttrType: "int"},
+			{ID: "weight", For: "edge", AttrName: "weight", AttrType: "int"},
+		},
+		Graph: graphMLGraph{ID: "tags", EdgeDefault: "undirected"},
+	}
+
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.Name] = id
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: id, Data: []graphMLData{
+			{Key: "name", Value: n.Name},
+			{Key: "bookmarks", Value: fmt.Sprint(n.Bookmarks)},
+		}})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: ids[e.Source],
+			Target: ids[e.Target],
+			Data:   []graphMLData{{Key: "weight", Value: fmt.Sprint(e.Weight)}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSON writes g as {"nodes": [...], "edges": [...]}, the node-link
+// shape D3 and NetworkX read.
+func WriteJSON(w io.Writer, g *Graph) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
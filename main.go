@@ -1,16 +1,25 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/daveonkels/clinkding/cmd"
 	assetsCmd "github.com/daveonkels/clinkding/cmd/assets"
+	backupCmd "github.com/daveonkels/clinkding/cmd/backup"
 	bookmarksCmd "github.com/daveonkels/clinkding/cmd/bookmarks"
 	bundlesCmd "github.com/daveonkels/clinkding/cmd/bundles"
 	configCmd "github.com/daveonkels/clinkding/cmd/config"
+	syncCmd "github.com/daveonkels/clinkding/cmd/sync"
 	tagsCmd "github.com/daveonkels/clinkding/cmd/tags"
 	userCmd "github.com/daveonkels/clinkding/cmd/user"
+	"github.com/daveonkels/clinkding/internal/client"
+	"github.com/daveonkels/clinkding/internal/exitcode"
 )
 
 var (
@@ -24,14 +33,53 @@ func main() {
 
 	// Register commands
 	cmd.AddCommand(assetsCmd.Cmd)
+	cmd.AddCommand(backupCmd.Cmd)
+	cmd.AddCommand(backupCmd.RestoreCmd)
 	cmd.AddCommand(bookmarksCmd.Cmd)
 	cmd.AddCommand(bundlesCmd.Cmd)
 	cmd.AddCommand(configCmd.Cmd)
+	cmd.AddCommand(syncCmd.Cmd)
 	cmd.AddCommand(tagsCmd.Cmd)
 	cmd.AddCommand(userCmd.Cmd)
 
-	if err := cmd.Execute(); err != nil {
+	// Cancel in-flight requests on Ctrl-C; a second Ctrl-C kills the process.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err := cmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(reportError(err))
+	}
+}
+
+// reportError prints err to stderr, as a JSON object when --json is set,
+// and returns the exit code for it.
+func reportError(err error) int {
+	code := exitcode.FromError(err)
+
+	if cmd.JSONRequested() {
+		report := map[string]interface{}{
+			"code":    code,
+			"kind":    exitcode.Kind(code),
+			"message": err.Error(),
+		}
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) {
+			report["status"] = apiErr.StatusCode
+		}
+
+		encoder := json.NewEncoder(os.Stderr)
+		_ = encoder.Encode(map[string]interface{}{"error": report})
+		return code
+	}
+
+	// Confirmation prompts already printed "Aborted."
+	if code != exitcode.Aborted {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
 	}
+	return code
 }